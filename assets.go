@@ -0,0 +1,94 @@
+package flash2
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fingerprintRegexp matches file names with content hash before extension,
+// e.g. "app.3f9a1c.js" or "app-3f9a1c.min.js"
+var fingerprintRegexp = regexp.MustCompile(`^(.+)[.-]([0-9a-f]{6,64})(\.[^.]+(?:\.[^.]+)*)$`)
+
+// IsFingerprinted returns true if file name contains content hash of
+// hex digits and letters, e.g. "app.3f9a1c.js"
+func IsFingerprinted(name string) bool {
+	_, ok := stripFingerprint(path.Base(name))
+	return ok
+}
+
+// stripFingerprint returns file base name without content hash
+func stripFingerprint(base string) (string, bool) {
+	m := fingerprintRegexp.FindStringSubmatch(base)
+	// hash has both digits and letters, so dates and ids are not hashes
+	if m == nil || !strings.ContainsAny(m[2], "0123456789") || !strings.ContainsAny(m[2], "abcdef") {
+		return base, false
+	}
+	return m[1] + m[3], true
+}
+
+// AssetManifest maps asset names to fingerprinted file names
+//
+//	m, err := flash2.NewAssetManifest(os.DirFS("public"))
+//	m.Path("js/app.js") // js/app.3f9a1c.js
+type AssetManifest map[string]string
+
+// NewAssetManifest builds AssetManifest from fingerprinted files in fsys.
+// If there are several versions of asset the most recent one is used.
+func NewAssetManifest(fsys fs.FS) (AssetManifest, error) {
+	m := AssetManifest{}
+	mtimes := map[string]time.Time{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isPrecompressed(p) {
+			return err
+		}
+		base, ok := stripFingerprint(path.Base(p))
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name := path.Join(path.Dir(p), base)
+		if t, ok := mtimes[name]; ok && !info.ModTime().After(t) {
+			return nil
+		}
+		m[name] = p
+		mtimes[name] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Path returns fingerprinted file name for asset.
+// Returns name if asset is not in manifest.
+// Leading slash is preserved, so it can be used as template func.
+func (m AssetManifest) Path(name string) string {
+	if strings.HasPrefix(name, "/") {
+		if p, ok := m[name[1:]]; ok {
+			return "/" + p
+		}
+		return name
+	}
+	if p, ok := m[name]; ok {
+		return p
+	}
+	return name
+}
+
+// isPrecompressed returns true if file is precompressed version of other file
+func isPrecompressed(name string) bool {
+	ext := path.Ext(name)
+	for _, e := range encodingExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package flash2
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestIsFingerprinted(t *testing.T) {
+	assertEqual(t, true, IsFingerprinted("js/app.3f9a1c.js"))
+	assertEqual(t, true, IsFingerprinted("app-3f9a1c0d.min.js"))
+	assertEqual(t, false, IsFingerprinted("js/app.js"))
+	assertEqual(t, false, IsFingerprinted("app.facade.js"))
+	assertEqual(t, false, IsFingerprinted("3f9a1c.js"))
+	assertEqual(t, false, IsFingerprinted("jquery-3.6.0.min.js"))
+	assertEqual(t, false, IsFingerprinted("invoice-123456.html"))
+	assertEqual(t, false, IsFingerprinted("report-20240101.pdf"))
+	assertEqual(t, false, IsFingerprinted("photo.123456.jpg"))
+}
+
+func TestNewAssetManifest(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"js/app.3f9a1c.js":    {Data: []byte("new"), ModTime: now},
+		"js/app.1b2c3d.js":    {Data: []byte("old"), ModTime: now.Add(-time.Hour)},
+		"js/app.3f9a1c.js.gz": {Data: []byte("gz"), ModTime: now},
+		"css/site.9a8b7c.css": {Data: []byte("css"), ModTime: now},
+		"index.html":          {Data: []byte("html"), ModTime: now},
+	}
+	m, err := NewAssetManifest(fsys)
+	assertNil(t, err)
+	assertEqual(t, 2, len(m))
+	assertEqual(t, "js/app.3f9a1c.js", m.Path("js/app.js"))
+	assertEqual(t, "/css/site.9a8b7c.css", m.Path("/css/site.css"))
+	assertEqual(t, "/index.html", m.Path("/index.html"))
+}
+
+func TestFileServerCacheRules(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/app.3f9a1c.js":       {Data: []byte("app")},
+		"static/app.3f9a1c.js.gz":    {Data: []byte("gzipped app")},
+		"static/page.html":           {Data: []byte("page")},
+		"static/page.3f9a1c.html":    {Data: []byte("page")},
+		"static/report-20240101.pdf": {Data: []byte("pdf")},
+		"static/logo.png":            {Data: []byte("png")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/static").FileServerFS(fsys, FileServerOptions{
+		Encodings:    []string{"gzip"},
+		CacheRules:   DefaultCacheRules,
+		CacheControl: "max-age=60",
+	})
+
+	req := newRequest("GET", "http://localhost/static/app.3f9a1c.js", "")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "gzipped app", w.Body.String())
+	assertEqual(t, ImmutableCache, w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/static/page.html", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/static/page.3f9a1c.html", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/static/report-20240101.pdf", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "max-age=60", w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/static/logo.png", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "max-age=60", w.Header().Get("Cache-Control"))
+}
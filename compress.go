@@ -0,0 +1,208 @@
+package flash2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// defaultCompressCacheSize is default compressed files cache size in bytes
+const defaultCompressCacheSize = 32 << 20
+
+// compressMinSize is minimum file size worth compressing
+const compressMinSize = 256
+
+// defaultCompressMaxSize is default maximum size of files compressed on the fly
+const defaultCompressMaxSize = 1 << 20
+
+// compressTypes are compressible non-text content types
+var compressTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/wasm",
+	"application/manifest+json",
+	"image/svg+xml",
+}
+
+// compressible returns true if content type is worth compressing
+func compressible(ctype string) bool {
+	if i := strings.IndexByte(ctype, ';'); i >= 0 {
+		ctype = ctype[:i]
+	}
+	ctype = strings.TrimSpace(ctype)
+	if strings.HasPrefix(ctype, "text/") {
+		return true
+	}
+	for _, t := range compressTypes {
+		if ctype == t {
+			return true
+		}
+	}
+	return false
+}
+
+// compressed serves gzip compressed file if client accepts it and file
+// type is compressible. Returns false if file should be served as is.
+func (f *fileHandler) compressed(w http.ResponseWriter, r *http.Request, name string) bool {
+	if !f.opts.Compress {
+		return false
+	}
+	addVary(w, "Accept-Encoding")
+	if !parseAcceptEncoding(r.Header.Get("Accept-Encoding")).accepts("gzip") {
+		return false
+	}
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if !compressible(ctype) || f.escapes(name) {
+		return false
+	}
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil || info.IsDir() || info.Size() < compressMinSize || info.Size() > f.compressMaxSize() {
+		return false
+	}
+
+	key := compressKey{path: name, encoding: "gzip", modTime: info.ModTime().UnixNano(), size: info.Size()}
+	b, ok := f.cache.get(key)
+	if !ok {
+		if b, err = gzipFile(f.fsys, name); err != nil {
+			return false
+		}
+		f.cache.put(key, b)
+	}
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Encoding", "gzip")
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(b))
+	return true
+}
+
+// compressMaxSize returns maximum size of files compressed on the fly
+func (f *fileHandler) compressMaxSize() int64 {
+	if f.opts.CompressMaxSize > 0 {
+		return f.opts.CompressMaxSize
+	}
+	return defaultCompressMaxSize
+}
+
+// gzipFile returns gzip compressed file content
+func gzipFile(fsys fs.FS, name string) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := io.Copy(zw, file); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addVary adds value to Vary header if it is not there yet
+func addVary(w http.ResponseWriter, v string) {
+	for _, s := range w.Header().Values("Vary") {
+		for _, p := range strings.Split(s, ",") {
+			if strings.EqualFold(strings.TrimSpace(p), v) {
+				return
+			}
+		}
+	}
+	w.Header().Add("Vary", v)
+}
+
+// compressKey identifies compressed file version
+type compressKey struct {
+	path     string
+	encoding string
+	modTime  int64
+	size     int64
+}
+
+type compressEntry struct {
+	key  compressKey
+	data []byte
+}
+
+// compressCache is LRU cache of compressed files bounded by total size.
+// Changed files get new keys, outdated versions are dropped on put.
+// nil cache stores nothing.
+type compressCache struct {
+	sync.Mutex
+	max   int64
+	size  int64
+	ll    *list.List
+	items map[compressKey]*list.Element
+	// latest maps path and encoding to key of cached version
+	latest map[[2]string]compressKey
+}
+
+// newCompressCache returns cache holding up to max bytes
+func newCompressCache(max int64) *compressCache {
+	return &compressCache{
+		max:    max,
+		ll:     list.New(),
+		items:  make(map[compressKey]*list.Element),
+		latest: make(map[[2]string]compressKey),
+	}
+}
+
+// get returns cached data for key
+func (c *compressCache) get(k compressKey) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.items[k]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*compressEntry).data, true
+}
+
+// put adds data to cache evicting least recently used entries
+func (c *compressCache) put(k compressKey, data []byte) {
+	if c == nil || int64(len(data)) > c.max {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if _, ok := c.items[k]; ok {
+		return
+	}
+	if old, ok := c.latest[[2]string{k.path, k.encoding}]; ok {
+		c.remove(c.items[old])
+	}
+	c.items[k] = c.ll.PushFront(&compressEntry{key: k, data: data})
+	c.latest[[2]string{k.path, k.encoding}] = k
+	c.size += int64(len(data))
+	for c.size > c.max {
+		c.remove(c.ll.Back())
+	}
+}
+
+// remove removes cache element
+func (c *compressCache) remove(e *list.Element) {
+	if e == nil {
+		return
+	}
+	ent := c.ll.Remove(e).(*compressEntry)
+	delete(c.items, ent.key)
+	if c.latest[[2]string{ent.key.path, ent.key.encoding}] == ent.key {
+		delete(c.latest, [2]string{ent.key.path, ent.key.encoding})
+	}
+	c.size -= int64(len(ent.data))
+}
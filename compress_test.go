@@ -0,0 +1,128 @@
+package flash2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCompressible(t *testing.T) {
+	assertEqual(t, true, compressible("text/css; charset=utf-8"))
+	assertEqual(t, true, compressible("image/svg+xml"))
+	assertEqual(t, true, compressible("application/json"))
+	assertEqual(t, false, compressible("image/png"))
+	assertEqual(t, false, compressible(""))
+}
+
+func TestCompressCache(t *testing.T) {
+	c := newCompressCache(10)
+	a := compressKey{path: "a", encoding: "gzip", modTime: 1}
+	b := compressKey{path: "b", encoding: "gzip", modTime: 1}
+	d := compressKey{path: "d", encoding: "gzip", modTime: 1}
+
+	c.put(a, []byte("aaaa"))
+	c.put(b, []byte("bbbb"))
+	_, ok := c.get(a)
+	assertEqual(t, true, ok)
+
+	// b is least recently used
+	c.put(d, []byte("dddd"))
+	_, ok = c.get(b)
+	assertEqual(t, false, ok)
+	_, ok = c.get(a)
+	assertEqual(t, true, ok)
+	assertEqual(t, int64(8), c.size)
+
+	// new version replaces old one
+	a2 := compressKey{path: "a", encoding: "gzip", modTime: 2}
+	c.put(a2, []byte("a2"))
+	_, ok = c.get(a)
+	assertEqual(t, false, ok)
+	data, _ := c.get(a2)
+	assertEqual(t, "a2", string(data))
+	assertEqual(t, int64(6), c.size)
+
+	// too large entries are not cached
+	c.put(compressKey{path: "big"}, make([]byte, 11))
+	assertEqual(t, 2, c.ll.Len())
+
+	var nc *compressCache
+	nc.put(a, []byte("a"))
+	_, ok = nc.get(a)
+	assertEqual(t, false, ok)
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	assertNil(t, err)
+	res, err := io.ReadAll(zr)
+	assertNil(t, err)
+	return string(res)
+}
+
+func TestFileServerCompress(t *testing.T) {
+	css := strings.Repeat("body{margin:0}", 50)
+	fsys := fstest.MapFS{
+		"static/site.css": {Data: []byte(css), ModTime: time.Now().Add(-time.Hour)},
+		"static/logo.png": {Data: bytes.Repeat([]byte{1}, 1000)},
+		"static/tiny.css": {Data: []byte("a{}")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/static").FileServerFS(fsys, FileServerOptions{Compress: true})
+
+	req := newRequest("GET", "http://localhost/static/site.css", "")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+	assertEqual(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+	assertEqual(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assertEqual(t, css, gunzip(t, w.Body.Bytes()))
+
+	// changed file is compressed again
+	css = strings.Repeat("p{color:red}", 50)
+	fsys["static/site.css"] = &fstest.MapFile{Data: []byte(css), ModTime: time.Now()}
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, css, gunzip(t, w.Body.Bytes()))
+
+	for _, p := range []string{"/static/logo.png", "/static/tiny.css"} {
+		req = newRequest("GET", "http://localhost"+p, "")
+		req.Header.Set("Accept-Encoding", "gzip")
+		w = newRecorder()
+		r.ServeHTTP(w, req)
+		assertEqual(t, "", w.Header().Get("Content-Encoding"))
+	}
+
+	req = newRequest("GET", "http://localhost/static/site.css", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "", w.Header().Get("Content-Encoding"))
+	assertEqual(t, css, w.Body.String())
+}
+
+func TestFileServerCompressMaxSize(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/big.json":   {Data: bytes.Repeat([]byte(" "), 4096)},
+		"static/small.json": {Data: bytes.Repeat([]byte(" "), 1024)},
+	}
+	r := NewRouter()
+	r.PathPrefix("/static").FileServerFS(fsys, FileServerOptions{Compress: true, CompressMaxSize: 2048})
+
+	req := newRequest("GET", "http://localhost/static/big.json", "")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "", w.Header().Get("Content-Encoding"))
+	assertEqual(t, 4096, w.Body.Len())
+
+	req = newRequest("GET", "http://localhost/static/small.json", "")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+}
@@ -44,13 +44,24 @@ var (
 )
 
 // handleRoute returns http handler function to process route
-func handleRoute(a *CtrAction, p params, funcs []MWFunc) http.HandlerFunc {
+func handleRoute(r *Router, a *CtrAction, p params, funcs []MWFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		c := ctxPool.Get().(*Ctx)
 		c.init(w, req, p)
+		c.router = r
 		c.Action = a.Name
 		c.Controller = a.Controller
 
+		if r != nil && r.Contract != nil {
+			ok, done := r.Contract.begin(c)
+			if !ok {
+				return
+			}
+			if done != nil {
+				defer done()
+			}
+		}
+
 		for _, f := range funcs {
 			if ok := f(c); !ok {
 				return
@@ -102,7 +113,9 @@ type Ctx struct {
 	// GZipMinBytes minimum size in bytes to encode (default: 0)
 	GZipMinBytes int
 
-	vars map[string]interface{}
+	vars   map[string]interface{}
+	router *Router
+	locale string
 }
 
 // initCtx initializing Ctx structure
@@ -122,6 +135,8 @@ func (c *Ctx) clear() {
 	c.Action = ""
 	c.Controller = ""
 	c.vars = nil
+	c.router = nil
+	c.locale = ""
 }
 
 // setIP extracting IP address from request
@@ -146,6 +161,26 @@ func (c *Ctx) LoadJSONRequest(v interface{}) {
 	json.NewDecoder(c.Req.Body).Decode(&v)
 }
 
+// LoadAndValidate decodes JSON request body into model and validates it
+// for groups (see Ctx.Validate). On failure it renders error to client
+// and returns false
+//
+//	func (p Pages) Create(c *flash2.Ctx) {
+//		m := Page{}
+//		if !c.LoadAndValidate(&m) {
+//			return
+//		}
+//		...
+//	}
+func (c *Ctx) LoadAndValidate(m BaseModel, groups ...string) bool {
+	if err := json.NewDecoder(c.Req.Body).Decode(m); err != nil && err != io.EOF {
+		c.RenderJSONError(http.StatusBadRequest, err.Error())
+		return false
+	}
+	m.ResetErrors()
+	return c.Validate(m, groups...)
+}
+
 // QueryParam returns URL query param
 func (c *Ctx) QueryParam(s string) string {
 	return c.Req.URL.Query().Get(s)
@@ -197,6 +232,11 @@ func (c *Ctx) Cookie(s string) string {
 
 // RenderJSON rendering JSON to client
 func (c *Ctx) RenderJSON(code int, i interface{}) {
+	c.renderJSON(code, i, "application/json; charset=utf-8")
+}
+
+// renderJSON marshals i and renders it with content type
+func (c *Ctx) renderJSON(code int, i interface{}, ctype string) {
 	var b []byte
 	var err error
 
@@ -205,12 +245,17 @@ func (c *Ctx) RenderJSON(code int, i interface{}) {
 		return
 	}
 
-	c.RenderRawJSON(code, b)
+	c.renderRawJSON(code, b, ctype)
 }
 
 // RenderRawJSON rendering raw JSON data to client
 func (c *Ctx) RenderRawJSON(code int, b []byte) {
-	c.W.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.renderRawJSON(code, b, "application/json; charset=utf-8")
+}
+
+// renderRawJSON rendering raw JSON data to client with content type
+func (c *Ctx) renderRawJSON(code int, b []byte, ctype string) {
+	c.W.Header().Set("Content-Type", ctype)
 
 	// gzip content if length > 5kb and client accepts gzip
 	if c.GZipEnabled && len(b) > c.GZipMinBytes && strings.Contains(c.Req.Header.Get("Accept-Encoding"), "gzip") {
@@ -225,11 +270,51 @@ func (c *Ctx) RenderRawJSON(code int, b []byte) {
 	}
 }
 
-// RenderJSONError rendering error to client in JSON format
+// RenderJSONError rendering error to client in JSON format.
+// If Router.ProblemJSON is enabled error is rendered as Problem.
 func (c *Ctx) RenderJSONError(code int, s string) {
+	if c.problems() {
+		c.RenderProblem(NewProblem(code, s))
+		return
+	}
 	c.RenderJSON(code, jsonErrors{Errors: errorMessages{Messages: []string{s}}})
 }
 
+// RenderJSONErrorCode rendering error message code translated to request
+// locale like RenderJSONError. Falls back to default English message.
+//
+//	c.RenderJSONErrorCode(422, "taken", nil) // {"errors":{"message":["has already been taken"]}}
+func (c *Ctx) RenderJSONErrorCode(code int, msgCode string, params JSON) {
+	c.RenderJSONError(code, c.T(msgCode, params))
+}
+
+// RenderModelErrors rendering model errors to client with 422 status.
+// Messages are translated to request locale if Router.Translator is set
+//
+//	{"errors":{"name":["can't be blank"],"address.city":["can't be blank"]}}
+//
+// or if Router.NestedErrors is set
+//
+//	{"errors":{"name":["can't be blank"],"address":{"city":["can't be blank"]}}}
+func (c *Ctx) RenderModelErrors(m BaseModel) {
+	c.renderModelErrors(http.StatusUnprocessableEntity, m)
+}
+
+// renderModelErrors rendering model errors to client with status code
+func (c *Ctx) renderModelErrors(code int, m BaseModel) {
+	if c.problems() {
+		p := NewProblem(code, c.T("validation_failed", nil))
+		p.Errors = c.translateErrors(m)
+		c.RenderProblem(p)
+		return
+	}
+	if c.router != nil && c.router.NestedErrors {
+		c.RenderJSON(code, JSON{"errors": nestErrors(c.translateErrors(m))})
+		return
+	}
+	c.RenderJSON(code, mErrors{Errors: c.translateErrors(m)})
+}
+
 // RenderString rendering string to client
 func (c *Ctx) RenderString(code int, s string) {
 	c.W.WriteHeader(code)
@@ -0,0 +1,251 @@
+package flash2
+
+import (
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPerPage is default directory listing page size
+const defaultPerPage = 100
+
+// maxPerPage limits per_page listing query param
+const maxPerPage = 1000
+
+// DirEntry is directory listing entry
+type DirEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modtime"`
+	IsDir   bool      `json:"is_dir"`
+}
+
+// DirListing is directory listing page
+type DirListing struct {
+	// Path is directory URL path
+	Path    string     `json:"path"`
+	Entries []DirEntry `json:"entries"`
+	// Sort is entries sort key: name, size or modtime
+	Sort string `json:"sort"`
+	// Order is entries sort order: asc or desc
+	Order   string `json:"order"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	// Total is number of entries in directory
+	Total int `json:"total"`
+}
+
+// Pages returns number of listing pages
+func (l DirListing) Pages() int {
+	if l.Total == 0 {
+		return 1
+	}
+	return (l.Total + l.PerPage - 1) / l.PerPage
+}
+
+// Query returns listing query string with page and sort changed
+func (l DirListing) Query(page int, sort, order string) string {
+	v := url.Values{}
+	v.Set("sort", sort)
+	v.Set("order", order)
+	v.Set("page", strconv.Itoa(page))
+	v.Set("per_page", strconv.Itoa(l.PerPage))
+	return "?" + v.Encode()
+}
+
+// DirRenderer renders directory listing
+type DirRenderer interface {
+	RenderDir(w http.ResponseWriter, r *http.Request, l DirListing)
+}
+
+// DirRendererFunc is function implementing DirRenderer
+type DirRendererFunc func(w http.ResponseWriter, r *http.Request, l DirListing)
+
+// RenderDir implements DirRenderer
+func (f DirRendererFunc) RenderDir(w http.ResponseWriter, r *http.Request, l DirListing) {
+	f(w, r, l)
+}
+
+// serveListing serves directory listing. Clients accepting JSON get
+// JSON listing, others get listing rendered with DirRenderer.
+// Supports sort (name, size, modtime), order (asc, desc), page and
+// per_page query params.
+func (f *fileHandler) serveListing(w http.ResponseWriter, r *http.Request, name string) {
+	dirs, err := fs.ReadDir(f.fsys, name)
+	if err != nil {
+		http.Error(w, "error reading directory", http.StatusInternalServerError)
+		return
+	}
+	upath := path.Clean(r.URL.Path)
+
+	entries := make([]DirEntry, 0, len(dirs))
+	for _, d := range dirs {
+		if f.denied(path.Join(upath, d.Name())) {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		e := DirEntry{Name: d.Name(), ModTime: info.ModTime(), IsDir: d.IsDir()}
+		if !e.IsDir {
+			e.Size = info.Size()
+		}
+		entries = append(entries, e)
+	}
+
+	q := r.URL.Query()
+	l := DirListing{
+		Path:    strings.TrimSuffix(upath, "/") + "/",
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
+		Page:    queryInt(q, "page", 1),
+		PerPage: queryInt(q, "per_page", defaultPerPage),
+		Total:   len(entries),
+	}
+	if l.PerPage > maxPerPage {
+		l.PerPage = maxPerPage
+	}
+	if l.Order != "desc" {
+		l.Order = "asc"
+	}
+	sortEntries(entries, &l.Sort, l.Order == "desc")
+
+	if l.Page > l.Pages() {
+		l.Page = l.Pages()
+	}
+	from := (l.Page - 1) * l.PerPage
+	to := from + l.PerPage
+	if to > len(entries) {
+		to = len(entries)
+	}
+	l.Entries = entries[from:to]
+
+	w.Header().Add("Vary", "Accept")
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(l)
+		return
+	}
+	if f.opts.DirRenderer != nil {
+		f.opts.DirRenderer.RenderDir(w, r, l)
+		return
+	}
+	renderDirHTML(w, r, l)
+}
+
+// sortEntries sorts entries by key, directories first.
+// Unknown key is replaced with name.
+func sortEntries(entries []DirEntry, key *string, desc bool) {
+	var less func(a, b DirEntry) bool
+	switch *key {
+	case "size":
+		less = func(a, b DirEntry) bool { return a.Size < b.Size }
+	case "modtime":
+		less = func(a, b DirEntry) bool { return a.ModTime.Before(b.ModTime) }
+	default:
+		*key = "name"
+		less = func(a, b DirEntry) bool { return a.Name < b.Name }
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// queryInt returns positive int query param or def
+func queryInt(q url.Values, key string, def int) int {
+	n, err := strconv.Atoi(q.Get(key))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
+
+// renderDirHTML renders listing with built-in HTML template
+func renderDirHTML(w http.ResponseWriter, r *http.Request, l DirListing) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dirTemplate.Execute(w, l); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var dirTemplate = template.Must(template.New("dir").Funcs(template.FuncMap{
+	"href": func(e DirEntry) string {
+		u := url.URL{Path: e.Name}
+		if e.IsDir {
+			return u.String() + "/"
+		}
+		return u.String()
+	},
+	"size": func(n int64) string {
+		units := []string{"B", "KB", "MB", "GB", "TB"}
+		f, i := float64(n), 0
+		for f >= 1024 && i < len(units)-1 {
+			f /= 1024
+			i++
+		}
+		if i == 0 {
+			return strconv.FormatInt(n, 10) + " B"
+		}
+		return strconv.FormatFloat(f, 'f', 1, 64) + " " + units[i]
+	},
+	"toggle": func(l DirListing, key string) string {
+		if l.Sort == key && l.Order == "asc" {
+			return "desc"
+		}
+		return "asc"
+	},
+	"add": func(a, b int) int { return a + b },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Index of {{.Path}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:2em;color:#24292e}
+h1{font-size:1.4em;font-weight:500}
+table{border-collapse:collapse;width:100%;max-width:960px}
+th,td{padding:.4em .8em;text-align:left;border-bottom:1px solid #eaecef}
+th a{color:inherit}
+td.size,th.size{text-align:right}
+a{color:#0366d6;text-decoration:none}
+a:hover{text-decoration:underline}
+.pages{margin-top:1em}
+</style>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<thead><tr>
+<th><a href="{{.Query 1 "name" (toggle . "name")}}">Name</a></th>
+<th class="size"><a href="{{.Query 1 "size" (toggle . "size")}}">Size</a></th>
+<th><a href="{{.Query 1 "modtime" (toggle . "modtime")}}">Modified</a></th>
+</tr></thead>
+<tbody>
+{{if ne .Path "/"}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{href .}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td class="size">{{if not .IsDir}}{{size .Size}}{{end}}</td><td>{{.ModTime.Format "2006-01-02 15:04:05"}}</td></tr>
+{{end}}</tbody>
+</table>
+{{if gt .Pages 1}}<div class="pages">
+{{if gt .Page 1}}<a href="{{.Query (add .Page -1) .Sort .Order}}">&laquo; prev</a>{{end}}
+page {{.Page}} of {{.Pages}}
+{{if lt .Page .Pages}}<a href="{{.Query (add .Page 1) .Sort .Order}}">next &raquo;</a>{{end}}
+</div>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,100 @@
+package flash2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func dirListingFS() fstest.MapFS {
+	now := time.Now()
+	return fstest.MapFS{
+		"pub/b.txt":      {Data: []byte("bb"), ModTime: now},
+		"pub/a.txt":      {Data: []byte("aaaa"), ModTime: now.Add(-time.Hour)},
+		"pub/c.txt":      {Data: []byte("c"), ModTime: now.Add(-2 * time.Hour)},
+		"pub/sub/d.txt":  {Data: []byte("d"), ModTime: now},
+		"pub/.secret":    {Data: []byte("s"), ModTime: now},
+		"pub/notes.bak":  {Data: []byte("n"), ModTime: now},
+		"pub/a <b>.html": {Data: []byte("x"), ModTime: now},
+	}
+}
+
+func TestDirListingJSON(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/").FileServerFS(dirListingFS(), FileServerOptions{DirList: true})
+
+	req := newRequest("GET", "http://localhost/pub/?sort=size&order=desc&per_page=2&page=2", "")
+	req.Header.Set("Accept", "application/json")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var l DirListing
+	assertNil(t, json.Unmarshal(w.Body.Bytes(), &l))
+	assertEqual(t, 5, l.Total)
+	assertEqual(t, 3, l.Pages())
+	assertEqual(t, 2, len(l.Entries))
+	assertEqual(t, "b.txt", l.Entries[0].Name)
+	assertEqual(t, int64(2), l.Entries[0].Size)
+	assertEqual(t, "a <b>.html", l.Entries[1].Name)
+
+	req = newRequest("GET", "http://localhost/pub/?sort=modtime", "")
+	req.Header.Set("Accept", "application/json")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertNil(t, json.Unmarshal(w.Body.Bytes(), &l))
+	assertEqual(t, "sub", l.Entries[0].Name)
+	assertEqual(t, true, l.Entries[0].IsDir)
+	assertEqual(t, "c.txt", l.Entries[1].Name)
+}
+
+func TestDirListingPageOutOfRange(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/").FileServerFS(dirListingFS(), FileServerOptions{DirList: true})
+
+	req := newRequest("GET", "http://localhost/pub/?page=9223372036854775807&per_page=2", "")
+	req.Header.Set("Accept", "application/json")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+
+	var l DirListing
+	assertNil(t, json.Unmarshal(w.Body.Bytes(), &l))
+	assertEqual(t, 3, l.Page)
+	assertEqual(t, 1, len(l.Entries))
+}
+
+func TestDirListingHTML(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/").FileServerFS(dirListingFS(), FileServerOptions{DirList: true})
+
+	req := newRequest("GET", "http://localhost/pub/", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	assertEqual(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assertEqual(t, true, strings.Contains(body, "Index of /pub/"))
+	assertEqual(t, true, strings.Contains(body, `href="sub/"`))
+	assertEqual(t, true, strings.Contains(body, "a &lt;b&gt;.html"))
+	assertEqual(t, false, strings.Contains(body, ".secret"))
+	assertEqual(t, false, strings.Contains(body, "notes.bak"))
+}
+
+func TestDirListingRenderer(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/").FileServerFS(dirListingFS(), FileServerOptions{
+		DirList: true,
+		DirRenderer: DirRendererFunc(func(w http.ResponseWriter, r *http.Request, l DirListing) {
+			w.Write([]byte(l.Path + " " + l.Entries[0].Name))
+		}),
+	})
+
+	req := newRequest("GET", "http://localhost/pub/", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "/pub/ sub", w.Body.String())
+}
@@ -1,52 +1,432 @@
 package flash2
 
 import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// FileServerOptions configures static files serving
+type FileServerOptions struct {
+	// DirList enables directory content listing (default: false)
+	DirList bool
+	// DirRenderer renders HTML directory listing, JSON listing is served
+	// to clients accepting JSON (default: built-in HTML template)
+	DirRenderer DirRenderer
+	// Encodings are precompressed encodings to look for in order of
+	// preference: "br" for .br, "zstd" for .zst and "gzip" for .gz files.
+	// Content-Type is derived from original file extension (default: none)
+	Encodings []string
+	// IndexFiles are file names served for directory requests
+	// (default: index.html)
+	IndexFiles []string
+	// CacheControl is Cache-Control header value for served files (default: none)
+	CacheControl string
+	// CacheRules are Cache-Control rules checked in order before
+	// CacheControl, first matching rule wins (see DefaultCacheRules)
+	CacheRules []CacheRule
+	// ServeHidden enables serving files and directories which names
+	// start with dot (default: false)
+	ServeHidden bool
+	// Deny are glob patterns of denied paths. Patterns without slash match
+	// any path element, e.g. "*.bak", patterns with slash match URL path
+	// and its parents, e.g. "/private/*" (default: DefaultDeny)
+	Deny []string
+	// Allow are glob patterns of paths served despite Deny and hidden
+	// files rules, e.g. "/.well-known/*"
+	Allow []string
+	// DenyStatus is response status for denied paths (default: 404)
+	DenyStatus int
+	// Compress enables gzip compression of compressible files which have
+	// no precompressed versions (default: false)
+	Compress bool
+	// CompressCacheSize is maximum total size in bytes of compressed files
+	// kept in memory, negative value disables cache (default: 32MB)
+	CompressCacheSize int64
+	// CompressMaxSize is maximum size in bytes of files compressed
+	// on the fly, larger files are served as is (default: 1MB)
+	CompressMaxSize int64
+	// NotFound handles requests for missing files (default: http.NotFound)
+	NotFound http.Handler
+	// SPA is index file path within root served with no-cache for missing
+	// extensionless paths, e.g. "/app/index.html" (default: none)
+	SPA string
+	// SPAExclude are URL path prefixes never falling back to SPA index,
+	// e.g. "/app/api"
+	SPAExclude []string
+}
+
+// CacheRule sets Cache-Control header for files matching rule
+type CacheRule struct {
+	// Match returns true if rule applies to file name
+	Match func(name string) bool
+	// Value is Cache-Control header value
+	Value string
+}
+
+// ImmutableCache is Cache-Control value for fingerprinted assets
+const ImmutableCache = "public, max-age=31536000, immutable"
+
+// DefaultCacheRules make clients revalidate HTML pages and cache
+// fingerprinted files forever
+var DefaultCacheRules = []CacheRule{
+	{Match: MatchExt(".html", ".htm"), Value: "no-cache"},
+	{Match: IsFingerprinted, Value: ImmutableCache},
+}
+
+// MatchExt returns CacheRule matcher for file extensions
+func MatchExt(exts ...string) func(string) bool {
+	return func(name string) bool {
+		ext := strings.ToLower(path.Ext(name))
+		for _, e := range exts {
+			if ext == e {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultDeny are backup and editor files denied by default
+var DefaultDeny = []string{"*~", "*.bak", "*.old", "*.orig", "*.swp", "#*#"}
+
+// encodingExts maps content encodings to precompressed file extensions
+var encodingExts = map[string]string{
+	"gzip": ".gz",
+	"br":   ".br",
+	"zstd": ".zst",
+}
+
 type fileHandler struct {
-	root         string
-	dirList, enc bool
+	fsys fs.FS
+	// root is file system directory, used to prevent symlink escapes
+	root  string
+	opts  FileServerOptions
+	cache *compressCache
 }
 
-func (f *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if !f.dirList {
-		if strings.HasSuffix(r.URL.Path, "/") {
-			http.NotFound(w, r)
-			return
+// newFileHandler returns file handler for fsys
+func newFileHandler(fsys fs.FS, root string, opts FileServerOptions) *fileHandler {
+	f := &fileHandler{fsys: fsys, root: root, opts: opts}
+	if opts.Compress && opts.CompressCacheSize >= 0 {
+		size := opts.CompressCacheSize
+		if size == 0 {
+			size = defaultCompressCacheSize
 		}
+		f.cache = newCompressCache(size)
 	}
+	return f
+}
 
+func (f *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	upath := r.URL.Path
 	if !strings.HasPrefix(upath, "/") {
 		upath = "/" + upath
 		r.URL.Path = upath
 	}
-	upath = path.Join(f.root, upath)
 	upath = path.Clean(upath)
 
-	if f.enc {
-		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			p := path.Ext(upath)
-			switch p {
-			case ".css", ".js":
-				if _, err := os.Stat(upath + ".gz"); err == nil {
-					upath = upath + ".gz"
-					if p == ".js" {
-						w.Header().Set("Content-Type", "application/javascript")
-					} else {
-						w.Header().Set("Content-Type", "text/css; charset=utf-8")
-					}
-					w.Header().Set("Content-Encoding", "gzip")
+	if f.denied(upath) {
+		f.deny(w, r)
+		return
+	}
+
+	name := fsName(upath)
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		if f.spaFallback(r, upath) {
+			f.serveSPA(w, r)
+			return
+		}
+		f.notFound(w, r)
+		return
+	}
+
+	if f.escapes(name) {
+		f.deny(w, r)
+		return
+	}
+
+	if info.IsDir() {
+		f.serveDir(w, r, name)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/") {
+		f.notFound(w, r)
+		return
+	}
+
+	f.setCacheControl(w, name)
+	f.serveEncoded(w, r, name)
+}
+
+// serveDir serves index file or directory listing
+func (f *fileHandler) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+		return
+	}
+	for _, idx := range f.indexFiles() {
+		p := path.Join(name, idx)
+		if info, err := fs.Stat(f.fsys, p); err == nil && !info.IsDir() {
+			f.setCacheControl(w, p)
+			f.serveEncoded(w, r, p)
+			return
+		}
+	}
+	if !f.opts.DirList {
+		f.notFound(w, r)
+		return
+	}
+	f.serveListing(w, r, name)
+}
+
+// spaFallback returns true if missing path should be served with SPA index
+func (f *fileHandler) spaFallback(r *http.Request, upath string) bool {
+	if f.opts.SPA == "" || (r.Method != "GET" && r.Method != "HEAD") {
+		return false
+	}
+	if path.Ext(upath) != "" {
+		return false
+	}
+	for _, p := range f.opts.SPAExclude {
+		p = strings.TrimSuffix(p, "/")
+		if upath == p || strings.HasPrefix(upath, p+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// serveSPA serves SPA index file. Index is never cached so clients
+// pick up new asset names after deploy.
+func (f *fileHandler) serveSPA(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	f.serveEncoded(w, r, fsName(path.Clean("/"+f.opts.SPA)))
+}
+
+// serveEncoded serves precompressed or compressed file version
+// accepted by client or file itself
+func (f *fileHandler) serveEncoded(w http.ResponseWriter, r *http.Request, name string) {
+	if p := f.precompressed(w, r, name); p != name {
+		f.serveFile(w, r, p)
+		return
+	}
+	if f.compressed(w, r, name) {
+		return
+	}
+	f.serveFile(w, r, name)
+}
+
+// precompressed returns name of precompressed file version accepted
+// by client and sets encoding headers. Returns name if there is none.
+func (f *fileHandler) precompressed(w http.ResponseWriter, r *http.Request, name string) string {
+	if len(f.opts.Encodings) == 0 {
+		return name
+	}
+	addVary(w, "Accept-Encoding")
+	accept := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	for _, enc := range f.opts.Encodings {
+		if !accept.accepts(enc) {
+			continue
+		}
+		ext, ok := encodingExts[enc]
+		if !ok {
+			continue
+		}
+		if info, err := fs.Stat(f.fsys, name+ext); err == nil && !info.IsDir() {
+			ctype := mime.TypeByExtension(path.Ext(name))
+			if ctype == "" {
+				ctype = "application/octet-stream"
+			}
+			w.Header().Set("Content-Type", ctype)
+			w.Header().Set("Content-Encoding", enc)
+			return name + ext
+		}
+	}
+	return name
+}
+
+// acceptEncoding maps encodings to their quality values
+type acceptEncoding map[string]float64
+
+// parseAcceptEncoding parses Accept-Encoding header value
+func parseAcceptEncoding(s string) acceptEncoding {
+	res := acceptEncoding{}
+	for _, part := range strings.Split(s, ",") {
+		q := 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			if v := strings.TrimSpace(part[i+1:]); strings.HasPrefix(v, "q=") {
+				if f, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = f
+				}
+			}
+			part = part[:i]
+		}
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			res[part] = q
+		}
+	}
+	return res
+}
+
+// accepts returns true if encoding is acceptable
+func (a acceptEncoding) accepts(enc string) bool {
+	if q, ok := a[enc]; ok {
+		return q > 0
+	}
+	return a["*"] > 0
+}
+
+// serveFile serves file content handling Range and conditional requests
+func (f *fileHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	if f.escapes(name) {
+		f.deny(w, r)
+		return
+	}
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		f.notFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		f.notFound(w, r)
+		return
+	}
+	rs, ok := file.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rs = bytes.NewReader(b)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), rs)
+}
+
+// setCacheControl sets Cache-Control header for file if configured
+func (f *fileHandler) setCacheControl(w http.ResponseWriter, name string) {
+	for _, rule := range f.opts.CacheRules {
+		if rule.Match(name) {
+			w.Header().Set("Cache-Control", rule.Value)
+			return
+		}
+	}
+	if f.opts.CacheControl != "" {
+		w.Header().Set("Cache-Control", f.opts.CacheControl)
+	}
+}
+
+// denied returns true if URL path is denied by options
+func (f *fileHandler) denied(upath string) bool {
+	if matchPath(f.opts.Allow, upath) {
+		return false
+	}
+	if !f.opts.ServeHidden && isHidden(upath) {
+		return true
+	}
+	deny := f.opts.Deny
+	if deny == nil {
+		deny = DefaultDeny
+	}
+	return matchPath(deny, upath)
+}
+
+// escapes returns true if file resolves outside of root via symlinks
+func (f *fileHandler) escapes(name string) bool {
+	if f.root == "" {
+		return false
+	}
+	root, err := filepath.EvalSymlinks(f.root)
+	if err != nil {
+		return true
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		// missing file is handled by caller
+		return false
+	}
+	rel, err := filepath.Rel(root, p)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// deny serves denied path response
+func (f *fileHandler) deny(w http.ResponseWriter, r *http.Request) {
+	if f.opts.DenyStatus == 0 || f.opts.DenyStatus == http.StatusNotFound {
+		f.notFound(w, r)
+		return
+	}
+	w.Header().Del("Content-Encoding")
+	w.Header().Del("Content-Type")
+	http.Error(w, http.StatusText(f.opts.DenyStatus), f.opts.DenyStatus)
+}
+
+// notFound serves not found response
+func (f *fileHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	w.Header().Del("Content-Encoding")
+	w.Header().Del("Content-Type")
+	if f.opts.NotFound != nil {
+		f.opts.NotFound.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// indexFiles returns directory index file names
+func (f *fileHandler) indexFiles() []string {
+	if f.opts.IndexFiles == nil {
+		return []string{"index.html"}
+	}
+	return f.opts.IndexFiles
+}
+
+// isHidden returns true if any path element starts with dot
+func isHidden(upath string) bool {
+	for _, p := range strings.Split(upath, "/") {
+		if strings.HasPrefix(p, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath returns true if URL path matches any of glob patterns
+func matchPath(patterns []string, upath string) bool {
+	for _, p := range patterns {
+		if !strings.Contains(p, "/") {
+			for _, e := range strings.Split(upath, "/") {
+				if ok, _ := path.Match(p, e); ok && e != "" {
+					return true
 				}
 			}
+			continue
+		}
+		for s := upath; s != "/" && s != "."; s = path.Dir(s) {
+			if ok, _ := path.Match(p, s); ok {
+				return true
+			}
 		}
 	}
+	return false
+}
 
-	http.ServeFile(w, r, upath)
+// fsName converts cleaned URL path to fs.FS name
+func fsName(upath string) string {
+	name := strings.TrimPrefix(upath, "/")
+	if name == "" {
+		return "."
+	}
+	return name
 }
 
 // fileServer returns a handler that serves HTTP requests
@@ -56,12 +436,24 @@ func (f *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 //
 // second boolean value is serving gzip encoded files. default false
 func fileServer(root string, bools []bool) http.Handler {
-	f := &fileHandler{root: root}
+	opts := FileServerOptions{}
 	if len(bools) > 0 {
-		f.dirList = bools[0]
+		opts.DirList = bools[0]
 	}
-	if len(bools) > 1 {
-		f.enc = bools[1]
+	if len(bools) > 1 && bools[1] {
+		opts.Encodings = []string{"gzip"}
 	}
-	return f
+	return fileServerDir(root, opts)
+}
+
+// fileServerDir returns a handler that serves HTTP requests
+// with the contents of the directory root.
+func fileServerDir(root string, opts FileServerOptions) http.Handler {
+	return newFileHandler(os.DirFS(root), root, opts)
+}
+
+// fileServerFS returns a handler that serves HTTP requests
+// with the contents of the file system fsys.
+func fileServerFS(fsys fs.FS, opts FileServerOptions) http.Handler {
+	return newFileHandler(fsys, "", opts)
 }
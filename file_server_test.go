@@ -1,8 +1,12 @@
 package flash2
 
 import (
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestFileServer(t *testing.T) {
@@ -14,3 +18,223 @@ func TestFileServer(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assertEqual(t, "FileServer test", strings.TrimSpace(w.Body.String()))
 }
+
+func TestFileServerFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/app.js":    {Data: []byte("app")},
+		"assets/app.js.gz": {Data: []byte("gzipped app")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/assets").FileServerFS(fsys, FileServerOptions{Encodings: []string{"gzip"}})
+
+	req := newRequest("GET", "http://localhost/assets/app.js", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "app", w.Body.String())
+
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "gzipped app", w.Body.String())
+	assertEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+	assertEqual(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+
+	req = newRequest("GET", "http://localhost/assets/", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+}
+
+func TestFileServerOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site/docs/index.html": {Data: []byte("index")},
+		"site/app.css":         {Data: []byte("css")},
+		"site/app.css.br":      {Data: []byte("brotli css")},
+		"site/app.css.gz":      {Data: []byte("gzipped css")},
+		"site/.env":            {Data: []byte("SECRET=1")},
+		"site/other/a.txt":     {Data: []byte("a")},
+		"site/.git/config":     {Data: []byte("git")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/site").FileServerFS(fsys, FileServerOptions{
+		Encodings:    []string{"br", "gzip"},
+		CacheControl: "public, max-age=60",
+		NotFound: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+			w.Write([]byte("custom not found"))
+		}),
+	})
+
+	req := newRequest("GET", "http://localhost/site/docs/", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "index", w.Body.String())
+	assertEqual(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/site/app.css", "")
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "brotli css", w.Body.String())
+	assertEqual(t, "br", w.Header().Get("Content-Encoding"))
+
+	for _, p := range []string{"/site/.env", "/site/.git/config", "/site/other/", "/site/missing.txt"} {
+		req = newRequest("GET", "http://localhost"+p, "")
+		w = newRecorder()
+		r.ServeHTTP(w, req)
+		assertEqual(t, 404, w.Code)
+		assertEqual(t, "custom not found", w.Body.String())
+	}
+}
+
+func TestFileServerWithOptions(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/").FileServerWithOptions("./test", FileServerOptions{DirList: true})
+
+	req := newRequest("GET", "http://localhost/files/", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, true, strings.Contains(w.Body.String(), "file.txt"))
+
+	req = newRequest("GET", "http://localhost/files", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 301, w.Code)
+}
+
+func TestFileServerPrecompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/report.json":     {Data: []byte(`{"a":1}`)},
+		"data/report.json.zst": {Data: []byte("zstd report")},
+		"data/report.json.gz":  {Data: []byte("gzipped report")},
+		"data/raw.bin":         {Data: []byte("raw")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/data").FileServerFS(fsys, FileServerOptions{Encodings: []string{"br", "zstd", "gzip"}})
+
+	req := newRequest("GET", "http://localhost/data/report.json", "")
+	req.Header.Set("Accept-Encoding", "gzip, zstd;q=0")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "gzipped report", w.Body.String())
+	assertEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+	assertEqual(t, "application/json", w.Header().Get("Content-Type"))
+	assertEqual(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	req.Header.Set("Accept-Encoding", "br, zstd, gzip")
+	req.Header.Set("Range", "bytes=0-3")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 206, w.Code)
+	assertEqual(t, "zstd", w.Body.String())
+	assertEqual(t, "zstd", w.Header().Get("Content-Encoding"))
+
+	req = newRequest("GET", "http://localhost/data/raw.bin", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "raw", w.Body.String())
+	assertEqual(t, "", w.Header().Get("Content-Encoding"))
+	assertEqual(t, "Accept-Encoding", w.Header().Get("Vary"))
+}
+
+func TestFileServerSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/index.html":     {Data: []byte("spa")},
+		"app/static/main.js": {Data: []byte("main")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/app").FileServerFS(fsys, FileServerOptions{
+		CacheControl: "max-age=3600",
+		SPA:          "/app/index.html",
+		SPAExclude:   []string{"/app/api/"},
+	})
+
+	req := newRequest("GET", "http://localhost/app/users/5", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, "spa", w.Body.String())
+	assertEqual(t, "no-cache", w.Header().Get("Cache-Control"))
+	assertEqual(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	req = newRequest("GET", "http://localhost/app/static/main.js", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "main", w.Body.String())
+	assertEqual(t, "max-age=3600", w.Header().Get("Cache-Control"))
+
+	req = newRequest("GET", "http://localhost/app/static/missing.js", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+
+	req = newRequest("GET", "http://localhost/app/api/users", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+
+	req = newRequest("POST", "http://localhost/app/users", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+}
+
+func TestFileServerDeny(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site/index.html":          {Data: []byte("index")},
+		"site/config.php.bak":      {Data: []byte("backup")},
+		"site/.env":                {Data: []byte("secret")},
+		"site/.well-known/acme":    {Data: []byte("token")},
+		"site/private/report.html": {Data: []byte("report")},
+	}
+	r := NewRouter()
+	r.PathPrefix("/site").FileServerFS(fsys, FileServerOptions{
+		Deny:  append([]string{"/site/private"}, DefaultDeny...),
+		Allow: []string{"/site/.well-known/*"},
+	})
+
+	for _, p := range []string{"/site/config.php.bak", "/site/.env", "/site/private/report.html"} {
+		req := newRequest("GET", "http://localhost"+p, "")
+		w := newRecorder()
+		r.ServeHTTP(w, req)
+		assertEqual(t, 404, w.Code)
+	}
+
+	req := newRequest("GET", "http://localhost/site/.well-known/acme", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "token", w.Body.String())
+
+	r = NewRouter()
+	r.PathPrefix("/site").FileServerFS(fsys, FileServerOptions{DenyStatus: 403})
+	req = newRequest("GET", "http://localhost/site/.env", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 403, w.Code)
+}
+
+func TestFileServerSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "public")
+	assertNil(t, os.MkdirAll(filepath.Join(root, "files"), 0755))
+	assertNil(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+	assertNil(t, os.WriteFile(filepath.Join(root, "files", "ok.txt"), []byte("ok"), 0644))
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "files", "leak.txt")); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	assertNil(t, os.Symlink("ok.txt", filepath.Join(root, "files", "link.txt")))
+
+	r := NewRouter()
+	r.PathPrefix("/files").FileServerWithOptions(root, FileServerOptions{})
+
+	req := newRequest("GET", "http://localhost/files/leak.txt", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+
+	req = newRequest("GET", "http://localhost/files/link.txt", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "ok", w.Body.String())
+}
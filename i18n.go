@@ -0,0 +1,197 @@
+package flash2
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Translator translates error codes to localized messages
+type Translator interface {
+	// Translate returns message for code in locale with params substituted.
+	// Returns false if there is no message for code in locale.
+	Translate(locale, code string, params JSON) (string, bool)
+	// Locales returns list of supported locales
+	Locales() []string
+}
+
+// Catalog is message catalog implementing Translator.
+// Maps locale to error code message templates with {param} placeholders.
+//
+//	cat := flash2.NewCatalog()
+//	cat.Add("de", map[string]string{"blank": "darf nicht leer sein"})
+//	r.Translator = cat
+type Catalog map[string]map[string]string
+
+// NewCatalog returns Catalog with default English messages
+func NewCatalog() Catalog {
+	en := make(map[string]string, len(messages))
+	for k, v := range messages {
+		en[k] = v
+	}
+	return Catalog{"en": en}
+}
+
+// Add adds messages for locale
+func (c Catalog) Add(locale string, msgs map[string]string) {
+	locale = strings.ToLower(locale)
+	if c[locale] == nil {
+		c[locale] = make(map[string]string, len(msgs))
+	}
+	for k, v := range msgs {
+		c[locale][k] = v
+	}
+}
+
+// Translate implements Translator. Falls back from region locale
+// to base language, e.g. "pt-br" to "pt".
+func (c Catalog) Translate(locale, code string, params JSON) (string, bool) {
+	locale = strings.ToLower(locale)
+	for {
+		if t, ok := c[locale][code]; ok {
+			return interpolate(t, params), true
+		}
+		i := strings.LastIndexByte(locale, '-')
+		if i < 0 {
+			return "", false
+		}
+		locale = locale[:i]
+	}
+}
+
+// Locales implements Translator
+func (c Catalog) Locales() []string {
+	res := make([]string, 0, len(c))
+	for k := range c {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Locale returns request locale selected from Accept-Language header
+// among Router.Translator locales. Returns Router.DefaultLocale if
+// nothing matches.
+func (c *Ctx) Locale() string {
+	if c.locale != "" {
+		return c.locale
+	}
+	c.locale = c.defaultLocale()
+	if t := c.translator(); t != nil {
+		if l := matchLocale(c.Header("Accept-Language"), t.Locales()); l != "" {
+			c.locale = l
+		}
+	}
+	return c.locale
+}
+
+// SetLocale overrides request locale
+func (c *Ctx) SetLocale(l string) {
+	c.locale = l
+}
+
+// T returns message for code translated to request locale.
+// Falls back to default English message if there is no translation.
+func (c *Ctx) T(code string, params JSON) string {
+	if s, ok := c.translate(code, params); ok {
+		return s
+	}
+	return formatMessage(code, params)
+}
+
+// translate returns message for code in request locale or default locale
+func (c *Ctx) translate(code string, params JSON) (string, bool) {
+	t := c.translator()
+	if t == nil {
+		return "", false
+	}
+	if s, ok := t.Translate(c.Locale(), code, params); ok {
+		return s, true
+	}
+	return t.Translate(c.defaultLocale(), code, params)
+}
+
+// translateErrors returns model errors translated to request locale
+func (c *Ctx) translateErrors(m BaseModel) modelErrors {
+	d, ok := m.(interface{ GetErrorDetails() errorDetails })
+	if !ok || c.translator() == nil {
+		return m.GetErrors()
+	}
+	res := make(modelErrors, len(d.GetErrorDetails()))
+	for f, errs := range d.GetErrorDetails() {
+		for _, e := range errs {
+			code := e.Code
+			if code == "" {
+				code = e.Message
+			}
+			s, ok := c.translate(code, e.Params)
+			if !ok {
+				s = e.Message
+			}
+			res[f] = append(res[f], s)
+		}
+	}
+	return res
+}
+
+// translator returns router translator
+func (c *Ctx) translator() Translator {
+	if c.router == nil {
+		return nil
+	}
+	return c.router.Translator
+}
+
+// defaultLocale returns router default locale
+func (c *Ctx) defaultLocale() string {
+	if c.router == nil || c.router.DefaultLocale == "" {
+		return "en"
+	}
+	return c.router.DefaultLocale
+}
+
+// matchLocale returns first supported locale from Accept-Language header
+// value ordered by quality. Base language matches are accepted both ways,
+// "pt-BR" matches "pt" and "pt" matches "pt-br".
+func matchLocale(header string, supported []string) string {
+	if header == "" || len(supported) == 0 {
+		return ""
+	}
+	type tag struct {
+		name string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		q := 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			if v := strings.TrimSpace(part[i+1:]); strings.HasPrefix(v, "q=") {
+				if f, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = f
+				}
+			}
+			part = strings.TrimSpace(part[:i])
+		}
+		if part != "" && part != "*" && q > 0 {
+			tags = append(tags, tag{strings.ToLower(part), q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		for _, s := range supported {
+			if strings.ToLower(s) == t.name {
+				return s
+			}
+		}
+		base := strings.SplitN(t.name, "-", 2)[0]
+		for _, s := range supported {
+			ls := strings.ToLower(s)
+			if ls == base || strings.SplitN(ls, "-", 2)[0] == base {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package flash2
+
+import "testing"
+
+func TestMatchLocale(t *testing.T) {
+	supported := []string{"de", "en", "pt-br"}
+	assertEqual(t, "de", matchLocale("fr;q=0.9, de;q=0.8, en;q=0.5", supported))
+	assertEqual(t, "en", matchLocale("de;q=0.4, en-US", supported))
+	assertEqual(t, "pt-br", matchLocale("pt", supported))
+	assertEqual(t, "", matchLocale("fr, *", supported))
+	assertEqual(t, "", matchLocale("", supported))
+}
+
+func TestCatalogTranslate(t *testing.T) {
+	cat := NewCatalog()
+	cat.Add("de", map[string]string{"too_short": "Mindestlänge ist {count}"})
+
+	s, ok := cat.Translate("de-AT", "too_short", JSON{"count": 6})
+	assertEqual(t, true, ok)
+	assertEqual(t, "Mindestlänge ist 6", s)
+
+	_, ok = cat.Translate("de", "blank", nil)
+	assertEqual(t, false, ok)
+}
+
+func TestRenderModelErrorsTranslated(t *testing.T) {
+	cat := NewCatalog()
+	cat.Add("de", map[string]string{
+		"blank":             "darf nicht leer sein",
+		"validation_failed": "Validierung fehlgeschlagen",
+		"not found":         "nicht gefunden",
+	})
+	r := &Router{Translator: cat}
+
+	req := newRequest("POST", "http://localhost/users", `{}`)
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+	w := newRecorder()
+	c := Ctx{router: r}
+	c.init(w, req, params{})
+	u := testUser{}
+	u.Valid()
+	u.AddError("name", "is custom")
+	c.RenderModelErrors(&u)
+	assertEqual(t, "de", c.Locale())
+	assertEqual(t, `{"errors":{"name":["darf nicht leer sein","is custom"]}}`, w.Body.String())
+
+	w = newRecorder()
+	c.init(w, req, params{})
+	c.RenderJSONErrorCode(404, "not found", nil)
+	assertEqual(t, `{"errors":{"message":["nicht gefunden"]}}`, w.Body.String())
+
+	// free-form messages are not translated
+	w = newRecorder()
+	c.init(w, req, params{})
+	c.RenderJSONError(404, "not found")
+	assertEqual(t, `{"errors":{"message":["not found"]}}`, w.Body.String())
+}
+
+func TestRenderJSONErrorCode(t *testing.T) {
+	req := newRequest("GET", "http://localhost/", "")
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	c.RenderJSONError(400, "invalid")
+	assertEqual(t, `{"errors":{"message":["invalid"]}}`, w.Body.String())
+
+	w = newRecorder()
+	c.init(w, req, params{})
+	c.RenderJSONErrorCode(422, "taken", nil)
+	assertEqual(t, 422, w.Code)
+	assertEqual(t, `{"errors":{"message":["has already been taken"]}}`, w.Body.String())
+}
@@ -0,0 +1,230 @@
+package flash2
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// listenFDsStart is first file descriptor passed by systemd
+const listenFDsStart = 3
+
+// Listener describes listener server is started on
+//
+//	r.ServeListeners(ctx,
+//		flash2.Listener{Addr: ":80", Handler: flash2.RedirectHTTPS("")},
+//		flash2.Listener{Addr: ":443", TLS: true},
+//		flash2.Listener{Network: "unix", Addr: "/run/app.sock"},
+//	)
+type Listener struct {
+	// Network is "tcp" or "unix" (default: "tcp")
+	Network string
+	// Addr is TCP address or Unix socket path (default: ":http" or ":https")
+	Addr string
+	// TLS enables SSL with Router.PublicKey and Router.PrivateKey
+	TLS bool
+	// Handler handles listener requests (default: Router)
+	Handler http.Handler
+	// Listener is already opened listener, Network and Addr are ignored
+	Listener net.Listener
+	// Name is file descriptor name of systemd listener
+	Name string
+}
+
+// listen opens listener
+func (l Listener) listen() (net.Listener, error) {
+	if l.Listener != nil {
+		return l.Listener, nil
+	}
+	network, addr := l.Network, l.Addr
+	if network == "" {
+		network = "tcp"
+	}
+	if network == "unix" {
+		if err := removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+	} else if addr == "" {
+		addr = ":http"
+		if l.TLS {
+			addr = ":https"
+		}
+	}
+	return net.Listen(network, addr)
+}
+
+// removeStaleSocket removes Unix socket file left by previous process.
+// Returns error if some process is still listening on socket.
+func removeStaleSocket(name string) error {
+	info, err := os.Stat(name)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	conn, err := net.DialTimeout("unix", name, time.Second)
+	if err == nil {
+		conn.Close()
+		return &net.OpError{Op: "listen", Net: "unix", Addr: &net.UnixAddr{Name: name, Net: "unix"}, Err: syscall.EADDRINUSE}
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return os.Remove(name)
+	}
+	return nil
+}
+
+// ServeListeners starts servers on all listeners and blocks until one of
+// them fails or ctx is done. All servers are shut down gracefully then.
+// Returns nil after graceful shutdown.
+func (r *Router) ServeListeners(ctx context.Context, listeners ...Listener) error {
+	return r.serveListeners(ctx, r.PublicKey, r.PrivateKey, listeners)
+}
+
+// serveListeners runs servers on listeners until one fails or ctx is done
+func (r *Router) serveListeners(ctx context.Context, certFile, keyFile string, listeners []Listener) error {
+	if len(listeners) == 0 {
+		return errors.New("flash2: no listeners")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var tlsCfg *tls.Config
+	for _, l := range listeners {
+		if l.TLS {
+			cfg, certs, err := r.tlsConfig(certFile, keyFile)
+			if err != nil {
+				return err
+			}
+			tlsCfg = cfg
+			go certs.watch(ctx, r.TLS, r.errorLog())
+			break
+		}
+	}
+
+	lns := make([]net.Listener, 0, len(listeners))
+	for _, l := range listeners {
+		ln, err := l.listen()
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return err
+		}
+		lns = append(lns, ln)
+	}
+
+	servers := make([]*http.Server, len(listeners))
+	errc := make(chan error, len(listeners))
+	for i, l := range listeners {
+		ln := lns[i]
+		srv := r.server(ln.Addr().String())
+		if l.Handler != nil {
+			srv.Handler = r.logHandler(l.Handler)
+		}
+		servers[i] = srv
+		go func(secure bool) {
+			if secure {
+				srv.TLSConfig = tlsCfg.Clone()
+				log.Printf("Starting secure SSL Server on %s", srv.Addr)
+				errc <- srv.ServeTLS(ln, "", "")
+			} else {
+				log.Printf("Starting Server on %s", srv.Addr)
+				errc <- srv.Serve(ln)
+			}
+		}(l.TLS)
+	}
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+	}
+	if serr := r.shutdown(servers...); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// SystemdListeners returns listeners passed by systemd socket activation
+// with LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES environment variables.
+// Returns nil if process was not socket activated.
+//
+//	ls, err := flash2.SystemdListeners()
+//	for i := range ls {
+//		ls[i].TLS = ls[i].Name == "https"
+//	}
+//	err = r.ServeListeners(ctx, ls...)
+func SystemdListeners() ([]Listener, error) {
+	ls, err := systemdListeners(os.Getenv, listenFDsStart)
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return ls, err
+}
+
+// systemdListeners returns listeners for file descriptors starting from start
+func systemdListeners(getenv func(string) string, start int) ([]Listener, error) {
+	if pid := getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	s := getenv("LISTEN_FDS")
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return nil, errors.New("flash2: invalid LISTEN_FDS " + s)
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+
+	var res []Listener
+	for i := 0; i < n; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(start+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(start+i), name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range res {
+				l.Listener.Close()
+			}
+			return nil, err
+		}
+		res = append(res, Listener{Listener: ln, Name: name})
+	}
+	return res, nil
+}
+
+// RedirectHTTPS returns handler redirecting requests to https URL
+// on port, empty port means default 443
+func RedirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := (&url.URL{Host: req.Host}).Hostname()
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		u := url.URL{
+			Scheme:   "https",
+			Host:     host,
+			Path:     req.URL.Path,
+			RawPath:  req.URL.RawPath,
+			RawQuery: req.URL.RawQuery,
+		}
+		code := http.StatusPermanentRedirect
+		if req.Method == "GET" || req.Method == "HEAD" {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, req, u.String(), code)
+	})
+}
@@ -0,0 +1,44 @@
+package flash2
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("network is not available")
+	}
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	assertNil(t, err)
+	defer f.Close()
+	// duplicate is closed by systemdListeners
+	fd, err := syscall.Dup(int(f.Fd()))
+	assertNil(t, err)
+
+	env := map[string]string{
+		"LISTEN_PID":     strconv.Itoa(os.Getpid()),
+		"LISTEN_FDS":     "1",
+		"LISTEN_FDNAMES": "api",
+	}
+	ls, err := systemdListeners(func(k string) string { return env[k] }, fd)
+	assertNil(t, err)
+	assertEqual(t, 1, len(ls))
+	assertEqual(t, "api", ls[0].Name)
+	assertEqual(t, ln.Addr().String(), ls[0].Listener.Addr().String())
+	ls[0].Listener.Close()
+
+	env["LISTEN_PID"] = "1"
+	ls, err = systemdListeners(func(k string) string { return env[k] }, fd)
+	assertNil(t, err)
+	assertEqual(t, 0, len(ls))
+
+	env["LISTEN_PID"], env["LISTEN_FDS"] = "", "x"
+	_, err = systemdListeners(func(k string) string { return env[k] }, fd)
+	assertNotNil(t, err)
+}
@@ -0,0 +1,140 @@
+package flash2
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// getBody returns response body of GET request
+func getBody(t *testing.T, client *http.Client, u string) string {
+	res, err := client.Get(u)
+	assertNil(t, err)
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	assertNil(t, err)
+	return string(b)
+}
+
+func TestServeListeners(t *testing.T) {
+	httpAddr, httpsAddr := freeAddr(t), freeAddr(t)
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "app.sock")
+	// stale socket file is removed
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skip("unix sockets are not supported")
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	certFile, keyFile := writeTestCert(t, dir, 1)
+	r := NewRouter()
+	r.LogHTTP = false
+	r.LogWriter = io.Discard
+	r.PublicKey, r.PrivateKey = certFile, keyFile
+	r.Get("/", func(c *Ctx) { c.RenderString(200, "hello") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- r.ServeListeners(ctx,
+			Listener{Addr: httpAddr, Handler: RedirectHTTPS("")},
+			Listener{Addr: httpsAddr, TLS: true},
+			Listener{Network: "unix", Addr: sock},
+		)
+	}()
+	waitServer(t, httpAddr)
+	waitServer(t, httpsAddr)
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get("http://" + httpAddr + "/a?b=1")
+	assertNil(t, err)
+	res.Body.Close()
+	assertEqual(t, 301, res.StatusCode)
+	assertEqual(t, "https://127.0.0.1/a?b=1", res.Header.Get("Location"))
+
+	assertEqual(t, "hello", getBody(t, client, "https://"+httpsAddr+"/"))
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	assertEqual(t, "hello", getBody(t, unixClient, "http://app/"))
+
+	cancel()
+	assertNil(t, <-errc)
+	_, err = os.Stat(sock)
+	assertEqual(t, true, os.IsNotExist(err))
+}
+
+func TestListenUnixInUse(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	l := Listener{Network: "unix", Addr: sock}
+	ln, err := l.listen()
+	if err != nil {
+		t.Skip("unix sockets are not supported")
+	}
+	defer ln.Close()
+
+	_, err = l.listen()
+	assertEqual(t, true, errors.Is(err, syscall.EADDRINUSE))
+
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", sock)
+	assertNil(t, err)
+	conn.Close()
+}
+
+func TestServeListenersError(t *testing.T) {
+	addr := freeAddr(t)
+	ln, err := net.Listen("tcp", addr)
+	assertNil(t, err)
+	defer ln.Close()
+
+	r := NewRouter()
+	err = r.ServeListeners(context.Background(), Listener{Addr: freeAddr(t)}, Listener{Addr: addr})
+	assertNotNil(t, err)
+	assertNotNil(t, r.ServeListeners(context.Background()))
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	h := RedirectHTTPS("8443")
+	req := newRequest("POST", "http://example.com:8080/pay?x=1", "")
+	w := newRecorder()
+	h.ServeHTTP(w, req)
+	assertEqual(t, 308, w.Code)
+	assertEqual(t, "https://example.com:8443/pay?x=1", w.Header().Get("Location"))
+
+	req = newRequest("GET", "http://[::1]:8080/", "")
+	w = newRecorder()
+	RedirectHTTPS("").ServeHTTP(w, req)
+	assertEqual(t, "https://[::1]/", w.Header().Get("Location"))
+
+	req = newRequest("GET", "http://[::1]/a?b=1", "")
+	w = newRecorder()
+	RedirectHTTPS("").ServeHTTP(w, req)
+	assertEqual(t, "https://[::1]/a?b=1", w.Header().Get("Location"))
+
+	req = newRequest("GET", "http://[::1]/a?b=1", "")
+	w = newRecorder()
+	RedirectHTTPS("8443").ServeHTTP(w, req)
+	assertEqual(t, "https://[::1]:8443/a?b=1", w.Header().Get("Location"))
+}
@@ -0,0 +1,57 @@
+package flash2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// messages contains English message templates keyed by error code.
+// Params are substituted in place of {name} placeholders.
+var messages = map[string]string{
+	"blank":                    "can't be blank",
+	"invalid":                  "invalid format",
+	"too_short":                "minimum length is {count}",
+	"too_long":                 "maximum length is {count}",
+	"greater_than":             "must be greater than {count}",
+	"greater_than_or_equal_to": "must be greater than or equal to {count}",
+	"less_than":                "must be less than {count}",
+	"less_than_or_equal_to":    "must be less than or equal to {count}",
+	"invalid_email":            "is not a valid email address",
+	"invalid_url":              "is not a valid URL",
+	"invalid_uuid":             "is not a valid UUID",
+	"invalid_ip":               "is not a valid IP address",
+	"invalid_cidr":             "is not a valid CIDR network",
+	"inclusion":                "is not included in the list",
+	"exclusion":                "is reserved",
+	"confirmation":             "doesn't match {attribute}",
+	"on_or_after":              "must be on or after {date}",
+	"on_or_before":             "must be on or before {date}",
+	"too_few":                  "must have at least {count} items",
+	"too_many":                 "must have at most {count} items",
+	"taken":                    "has already been taken",
+	"validation_failed":        "validation failed",
+	"invalid_type":             "must be {type}",
+	"invalid_json":             "is not valid JSON",
+	"unknown_property":         "is not allowed",
+}
+
+// formatMessage returns message for code with params substituted.
+// Unknown codes are returned as is.
+func formatMessage(code string, params JSON) string {
+	t, ok := messages[code]
+	if !ok {
+		t = code
+	}
+	return interpolate(t, params)
+}
+
+// interpolate replaces {name} placeholders in t with params values
+func interpolate(t string, params JSON) string {
+	if len(params) == 0 || !strings.Contains(t, "{") {
+		return t
+	}
+	for k, v := range params {
+		t = strings.Replace(t, "{"+k+"}", fmt.Sprint(v), -1)
+	}
+	return t
+}
@@ -1,17 +1,27 @@
 package flash2
 
 import (
-	"fmt"
 	"regexp"
 	"unicode/utf8"
 )
 
 type mErrors struct {
-	Errors modelErrors `json:"errors"`
+	Errors  modelErrors  `json:"errors"`
+	Details errorDetails `json:"-"`
 }
 
 type modelErrors map[string][]string
 
+type errorDetails map[string][]ValidationError
+
+// ValidationError is machine readable model error.
+// Code and Params identify the error, Message is its English text.
+type ValidationError struct {
+	Code    string `json:"code,omitempty"`
+	Params  JSON   `json:"params,omitempty"`
+	Message string `json:"message"`
+}
+
 // ModelBase structure for base model with error valiadtions
 //	type User struct {
 // 			ID   int64
@@ -24,15 +34,29 @@ type ModelBase struct {
 
 // ResetErrors clean all model errors
 func (m *ModelBase) ResetErrors() {
-	m.Errors = mErrors{Errors: make(modelErrors)}
+	m.Errors = mErrors{Errors: make(modelErrors), Details: make(errorDetails)}
 }
 
 // AddError adding error to model
 func (m *ModelBase) AddError(f string, t string) {
+	m.addError(f, ValidationError{Message: t})
+}
+
+// AddErrorCode adding error with code and params to model.
+// Message is built from code message template
+//
+//	m.AddErrorCode("password", "too_short", flash2.JSON{"count": 6})
+func (m *ModelBase) AddErrorCode(f, code string, params JSON) {
+	m.addError(f, ValidationError{Code: code, Params: params, Message: formatMessage(code, params)})
+}
+
+// addError adding validation error to model
+func (m *ModelBase) addError(f string, e ValidationError) {
 	if m.IsValid() {
-		m.Errors = mErrors{Errors: make(modelErrors)}
+		m.ResetErrors()
 	}
-	m.Errors.Errors[f] = append(m.Errors.Errors[f], t)
+	m.Errors.Errors[f] = append(m.Errors.Errors[f], e.Message)
+	m.Errors.Details[f] = append(m.Errors.Details[f], e)
 }
 
 // IsValid returns true if no errors on model
@@ -54,16 +78,27 @@ func (m *ModelBase) GetErrors() modelErrors {
 	return m.Errors.Errors
 }
 
+// GetErrorDetails returns model errors with codes and params
+func (m *ModelBase) GetErrorDetails() errorDetails {
+	return m.Errors.Details
+}
+
 // SetErrors set model errors
 func (m *ModelBase) SetErrors(e modelErrors) {
 	m.Errors.Errors = e
+	m.Errors.Details = make(errorDetails, len(e))
+	for f, msgs := range e {
+		for _, t := range msgs {
+			m.Errors.Details[f] = append(m.Errors.Details[f], ValidationError{Message: t})
+		}
+	}
 }
 
 // ValidatePresence validates string for presence
 // 	m.ValidatePresence("Name", m.Name)
 func (m *ModelBase) ValidatePresence(f, v string) {
 	if utf8.RuneCountInString(v) == 0 {
-		m.AddError(f, "can't be blank")
+		m.AddErrorCode(f, "blank", nil)
 	}
 }
 
@@ -72,81 +107,54 @@ func (m *ModelBase) ValidatePresence(f, v string) {
 func (m *ModelBase) ValidateLength(f, v string, min, max int) {
 	if min > 0 {
 		if utf8.RuneCountInString(v) < min {
-			m.AddError(f, fmt.Sprint("minimum length is", min))
+			m.AddErrorCode(f, "too_short", JSON{"count": min})
 		}
 	}
 	if max > 0 {
 		if utf8.RuneCountInString(v) > max {
-			m.AddError(f, fmt.Sprint("maximum length is", max))
+			m.AddErrorCode(f, "too_long", JSON{"count": max})
 		}
 	}
 }
 
-// ValidateInt validates int min, max. -1 for any
+// ValidateInt validates int min, max inclusive. -1 for any
 // 	m.ValidateInt("number", 10, -1, 11)  // max 18
 func (m *ModelBase) ValidateInt(f string, v, min, max int) {
-	if min > 0 {
-		if v < min {
-			m.AddError(f, fmt.Sprint("minimum length is", min))
-		}
-	}
-	if max > 0 {
-		if v > max {
-			m.AddError(f, fmt.Sprint("maximum length is", max))
-		}
-	}
+	ValidateRange(m, f, v, legacyBound(min), legacyBound(max))
 }
 
-// ValidateInt64 validates int64 min, max. -1 for any
+// ValidateInt64 validates int64 min, max inclusive. -1 for any
 // 	m.ValidateInt64("number", 10, 6, -1) // min 6
 func (m *ModelBase) ValidateInt64(f string, v, min, max int64) {
-	if min > 0 {
-		if v < min {
-			m.AddError(f, fmt.Sprint("minimum length is", min))
-		}
-	}
-	if max > 0 {
-		if v > max {
-			m.AddError(f, fmt.Sprint("maximum length is", max))
-		}
-	}
+	ValidateRange(m, f, v, legacyBound(min), legacyBound(max))
 }
 
-// ValidateFloat32 validates float32 min, max. -1 for any
+// ValidateFloat32 validates float32 min, max inclusive. -1 for any
 // 	m.ValidateFloat32("number", 10.2, -1, 11)
 func (m *ModelBase) ValidateFloat32(f string, v, min, max float32) {
-	if min > 0 {
-		if v < min {
-			m.AddError(f, fmt.Sprint("minimum length is", min))
-		}
-	}
-	if max > 0 {
-		if v > max {
-			m.AddError(f, fmt.Sprint("maximum length is", max))
-		}
-	}
+	ValidateRange(m, f, v, legacyBound(min), legacyBound(max))
 }
 
-// ValidateFloat64 validates float64 min, max. -1 for any
+// ValidateFloat64 validates float64 min, max inclusive. -1 for any
 // 	m.ValidateFloat64("number", 10.2, -1, 11)
 func (m *ModelBase) ValidateFloat64(f string, v, min, max float64) {
-	if min > 0 {
-		if v < min {
-			m.AddError(f, fmt.Sprint("minimum length is", min))
-		}
-	}
-	if max > 0 {
-		if v > max {
-			m.AddError(f, fmt.Sprint("maximum length is", max))
-		}
-	}
+	ValidateRange(m, f, v, legacyBound(min), legacyBound(max))
 }
 
-// ValidateFormat validates string format with regex string
+// ValidateFormat validates string format with regex string.
+// Compiled regex is cached, invalid regex causes panic
 // 	m.ValidateFormat("ip address", u.IP, `\A(\d{1,3}\.){3}\d{1,3}\z`)
 func (m *ModelBase) ValidateFormat(f, v, reg string) {
-	if r, _ := regexp.MatchString(reg, v); !r {
-		m.AddError(f, "invalid format")
+	m.ValidateFormatRegexp(f, v, cachedRegexp(reg))
+}
+
+// ValidateFormatRegexp validates string format with precompiled regex
+//
+//	var ipRegexp = regexp.MustCompile(`\A(\d{1,3}\.){3}\d{1,3}\z`)
+//	m.ValidateFormatRegexp("ip address", u.IP, ipRegexp)
+func (m *ModelBase) ValidateFormatRegexp(f, v string, r *regexp.Regexp) {
+	if !r.MatchString(v) {
+		m.AddErrorCode(f, "invalid", nil)
 	}
 }
 
@@ -158,3 +166,18 @@ type BaseModel interface {
 	GetErrors() modelErrors
 	ResetErrors()
 }
+
+// errorCoder is implemented by models storing error codes, like ModelBase
+type errorCoder interface {
+	AddErrorCode(string, string, JSON)
+}
+
+// addErrorCode adds error with code to model. Models without AddErrorCode
+// get English message added with AddError
+func addErrorCode(m BaseModel, f, code string, params JSON) {
+	if e, ok := m.(errorCoder); ok {
+		e.AddErrorCode(f, code, params)
+		return
+	}
+	m.AddError(f, formatMessage(code, params))
+}
@@ -0,0 +1,96 @@
+package flash2
+
+import "context"
+
+type ctxKey struct{}
+
+type validationGroupKey struct{}
+
+// ContextValidator is implemented by models which need request context
+// for validation. Ctx helpers call ValidWithContext instead of Valid
+// when model implements it
+//
+//	func (p *Page) ValidWithContext(ctx context.Context) bool {
+//		c := flash2.CtxFromContext(ctx)
+//		if p.OwnerID != c.Var("user_id") {
+//			p.AddError("owner_id", "must be current user")
+//		}
+//		if flash2.InValidationGroup(ctx, "create") {
+//			p.ValidatePresence("name", p.Name)
+//		}
+//		return p.IsValid()
+//	}
+type ContextValidator interface {
+	ValidWithContext(ctx context.Context) bool
+}
+
+// Context returns request context carrying Ctx. Use CtxFromContext to get it back
+func (c *Ctx) Context() context.Context {
+	return context.WithValue(c.Req.Context(), ctxKey{}, c)
+}
+
+// CtxFromContext returns Ctx stored in context by Ctx.Context or nil
+func CtxFromContext(ctx context.Context) *Ctx {
+	c, _ := ctx.Value(ctxKey{}).(*Ctx)
+	return c
+}
+
+// WithValidationGroup returns context with validation group set
+func WithValidationGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, validationGroupKey{}, group)
+}
+
+// ValidationGroup returns validation group from context or empty string
+func ValidationGroup(ctx context.Context) string {
+	g, _ := ctx.Value(validationGroupKey{}).(string)
+	return g
+}
+
+// InValidationGroup returns true if context validation group is one of groups
+func InValidationGroup(ctx context.Context, groups ...string) bool {
+	return inList(ValidationGroup(ctx), groups)
+}
+
+// ValidateModel validates model with ValidWithContext if implemented or Valid
+// followed by "validate" tag rules of validation group.
+// Nested models in fields, pointers and slices are validated as well and
+// their errors are added to model under keys like "address.city" or
+// "items[2].qty". If groups are given model is validated for each group
+// in order and validation stops on the first group with errors
+//
+//	flash2.ValidateModel(ctx, page, "update")
+func ValidateModel(ctx context.Context, m BaseModel, groups ...string) bool {
+	if len(groups) == 0 {
+		return validateTree(ctx, m)
+	}
+	for _, g := range groups {
+		if !validateTree(WithValidationGroup(ctx, g), m) {
+			return false
+		}
+	}
+	return true
+}
+
+// validModel calls model validation function. Valid has no access to
+// validation group, so "on=" tag rules of the group are applied after it.
+func validModel(ctx context.Context, m BaseModel) bool {
+	if v, ok := m.(ContextValidator); ok {
+		return v.ValidWithContext(ctx)
+	}
+	ok := m.Valid()
+	if g := ValidationGroup(ctx); g != "" {
+		validateGroupRules(m, g)
+		ok = ok && len(m.GetErrors()) == 0
+	}
+	return ok
+}
+
+// Validate validates model in request context for groups and renders
+// errors on failure. Returns false if model is not valid
+func (c *Ctx) Validate(m BaseModel, groups ...string) bool {
+	if !ValidateModel(c.Context(), m, groups...) {
+		c.RenderModelErrors(m)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package flash2
+
+import (
+	"context"
+	"testing"
+)
+
+type ctxPage struct {
+	Name    string `json:"name" validate:"on=create,required"`
+	OwnerID int    `json:"owner_id"`
+	ModelBase
+}
+
+func (p *ctxPage) ValidWithContext(ctx context.Context) bool {
+	ValidateContext(ctx, p)
+	if c := CtxFromContext(ctx); c != nil && c.Var("user_id") != p.OwnerID {
+		p.AddError("owner_id", "must be current user")
+	}
+	return p.IsValid()
+}
+
+func TestValidateModelGroups(t *testing.T) {
+	p := ctxPage{}
+	assertEqual(t, true, ValidateModel(context.Background(), &p))
+	assertEqual(t, true, ValidateModel(context.Background(), &p, "update"))
+	assertEqual(t, false, ValidateModel(context.Background(), &p, "create", "update"))
+	assertEqual(t, []string{"can't be blank"}, p.GetErrors()["name"])
+}
+
+func TestLoadAndValidateWithContext(t *testing.T) {
+	req := newRequest("PATCH", "http://localhost/pages/1", `{"owner_id":2}`)
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	c.SetVar("user_id", 1)
+	p := ctxPage{}
+	assertEqual(t, false, c.LoadAndValidate(&p, "update"))
+	assertEqual(t, `{"errors":{"owner_id":["must be current user"]}}`, w.Body.String())
+
+	req = newRequest("PATCH", "http://localhost/pages/1", `{"owner_id":1}`)
+	w = newRecorder()
+	c.init(w, req, params{})
+	p = ctxPage{}
+	assertEqual(t, true, c.LoadAndValidate(&p, "update"))
+
+	req = newRequest("POST", "http://localhost/pages", `{"owner_id":1}`)
+	w = newRecorder()
+	c.init(w, req, params{})
+	p = ctxPage{}
+	assertEqual(t, false, c.LoadAndValidate(&p, "create"))
+	assertEqual(t, `{"errors":{"name":["can't be blank"]}}`, w.Body.String())
+}
+
+type tagPage struct {
+	Name  string `json:"name" validate:"on=create,required"`
+	Title string `json:"title" validate:"required"`
+	ModelBase
+}
+
+func (p *tagPage) Valid() bool {
+	return Validate(p)
+}
+
+func TestLoadAndValidateTagGroups(t *testing.T) {
+	req := newRequest("POST", "http://localhost/pages", `{}`)
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	p := tagPage{}
+	assertEqual(t, false, c.LoadAndValidate(&p, "create"))
+	assertEqual(t, `{"errors":{"name":["can't be blank"],"title":["can't be blank"]}}`, w.Body.String())
+
+	req = newRequest("PATCH", "http://localhost/pages/1", `{"title":"page"}`)
+	w = newRecorder()
+	c.init(w, req, params{})
+	p = tagPage{}
+	assertEqual(t, true, c.LoadAndValidate(&p, "update"))
+}
@@ -0,0 +1,123 @@
+package flash2
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var baseModelType = reflect.TypeOf((*BaseModel)(nil)).Elem()
+
+// validateTree validates model and its nested models merging nested
+// errors into model under path keys like "address.city" or "items[2].qty"
+func validateTree(ctx context.Context, m BaseModel) bool {
+	ok := validModel(ctx, m)
+	v := reflect.Indirect(reflect.ValueOf(m))
+	if v.Kind() == reflect.Struct {
+		nestedModels(v, "", func(key string, child BaseModel) {
+			child.ResetErrors()
+			validateTree(ctx, child)
+			mergeErrors(m, key, child)
+		})
+	}
+	return ok && len(m.GetErrors()) == 0
+}
+
+// nestedModels calls fn for each BaseModel found in struct fields,
+// pointers, slices and arrays of struct v
+func nestedModels(v reflect.Value, prefix string, fn func(string, BaseModel)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Anonymous {
+			continue
+		}
+		nestedValue(v.Field(i), prefix+fieldName(sf), fn)
+	}
+}
+
+// nestedValue calls fn for v if it is BaseModel or descends into it
+func nestedValue(v reflect.Value, key string, fn func(string, BaseModel)) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Type().Implements(baseModelType) && v.Elem().Kind() == reflect.Struct {
+			fn(key, v.Interface().(BaseModel))
+			return
+		}
+		nestedValue(v.Elem(), key, fn)
+	case reflect.Struct:
+		if v.CanAddr() && v.Addr().Type().Implements(baseModelType) {
+			fn(key, v.Addr().Interface().(BaseModel))
+			return
+		}
+		nestedModels(v, key+".", fn)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			nestedValue(v.Index(i), fmt.Sprintf("%s[%d]", key, i), fn)
+		}
+	}
+}
+
+// mergeErrors adds child model errors to m with prefix key
+func mergeErrors(m BaseModel, key string, child BaseModel) {
+	if d, ok := child.(interface{ GetErrorDetails() errorDetails }); ok && d.GetErrorDetails() != nil {
+		for f, errs := range d.GetErrorDetails() {
+			for _, e := range errs {
+				if e.Code != "" {
+					addErrorCode(m, key+"."+f, e.Code, e.Params)
+				} else {
+					m.AddError(key+"."+f, e.Message)
+				}
+			}
+		}
+		return
+	}
+	for f, msgs := range child.GetErrors() {
+		for _, s := range msgs {
+			m.AddError(key+"."+f, s)
+		}
+	}
+}
+
+// nestErrors converts path keyed errors to nested structure matching
+// model JSON: "address.city" becomes {"address":{"city":[...]}} and
+// "items[2].qty" becomes {"items":{"2":{"qty":[...]}}}.
+// Errors of field which also has nested errors are kept under "_errors" key.
+func nestErrors(e modelErrors) JSON {
+	res := JSON{}
+	for k, msgs := range e {
+		n := res
+		path := errorPath(k)
+		for i, seg := range path {
+			if i == len(path)-1 {
+				if child, ok := n[seg].(JSON); ok {
+					child["_errors"] = msgs
+				} else {
+					n[seg] = msgs
+				}
+				break
+			}
+			child, ok := n[seg].(JSON)
+			if !ok {
+				child = JSON{}
+				if s, ok := n[seg].([]string); ok {
+					child["_errors"] = s
+				}
+				n[seg] = child
+			}
+			n = child
+		}
+	}
+	return res
+}
+
+// errorPath splits error key like "items[2].qty" into ["items", "2", "qty"]
+func errorPath(k string) []string {
+	return strings.FieldsFunc(k, func(r rune) bool {
+		return r == '.' || r == '[' || r == ']'
+	})
+}
@@ -0,0 +1,86 @@
+package flash2
+
+import (
+	"context"
+	"testing"
+)
+
+type nAddress struct {
+	City string `json:"city"`
+	ModelBase
+}
+
+func (a *nAddress) Valid() bool {
+	a.ValidatePresence("city", a.City)
+	return a.IsValid()
+}
+
+type nItem struct {
+	Qty int `json:"qty" validate:"min=1"`
+	ModelBase
+}
+
+func (i *nItem) Valid() bool {
+	return Validate(i)
+}
+
+type nOrder struct {
+	Name    string    `json:"name" validate:"required"`
+	Address nAddress  `json:"address"`
+	Billing *nAddress `json:"billing"`
+	Items   []nItem   `json:"items"`
+	ModelBase
+}
+
+func (o *nOrder) Valid() bool {
+	return Validate(o)
+}
+
+func TestValidateNestedModels(t *testing.T) {
+	o := nOrder{
+		Name:    "order",
+		Address: nAddress{City: "Paris"},
+		Items:   []nItem{{Qty: 1}},
+	}
+	assertEqual(t, true, ValidateModel(context.Background(), &o))
+
+	o = nOrder{
+		Billing: &nAddress{},
+		Items:   []nItem{{Qty: 1}, {Qty: 1}, {Qty: 0}},
+	}
+	assertEqual(t, false, ValidateModel(context.Background(), &o))
+	assertEqual(t, modelErrors{
+		"name":         {"can't be blank"},
+		"address.city": {"can't be blank"},
+		"billing.city": {"can't be blank"},
+		"items[2].qty": {"must be greater than or equal to 1"},
+	}, o.GetErrors())
+	assertEqual(t, "greater_than_or_equal_to", o.GetErrorDetails()["items[2].qty"][0].Code)
+}
+
+func TestNestErrors(t *testing.T) {
+	e := modelErrors{
+		"name":         {"can't be blank"},
+		"address":      {"is invalid"},
+		"address.city": {"can't be blank"},
+		"items[2].qty": {"must be greater than or equal to 1"},
+	}
+	assertEqual(t, JSON{
+		"name": []string{"can't be blank"},
+		"address": JSON{
+			"_errors": []string{"is invalid"},
+			"city":    []string{"can't be blank"},
+		},
+		"items": JSON{"2": JSON{"qty": []string{"must be greater than or equal to 1"}}},
+	}, nestErrors(e))
+}
+
+func TestRenderNestedModelErrors(t *testing.T) {
+	req := newRequest("POST", "http://localhost/orders", `{"name":"order","items":[{"qty":0}]}`)
+	w := newRecorder()
+	c := Ctx{router: &Router{NestedErrors: true}}
+	c.init(w, req, params{})
+	o := nOrder{}
+	assertEqual(t, false, c.LoadAndValidate(&o))
+	assertEqual(t, `{"errors":{"address":{"city":["can't be blank"]},"items":{"0":{"qty":["must be greater than or equal to 1"]}}}}`, w.Body.String())
+}
@@ -140,3 +140,111 @@ func TestModelValidateFormat(t *testing.T) {
 	m.ValidateFormat("IP", "1.1.1", `\A(\d{1,3}\.){3}\d{1,3}\z`)
 	assertEqual(t, m.IsValid(), false)
 }
+
+type testUser struct {
+	Name string `json:"name"`
+	ModelBase
+}
+
+func (u *testUser) Valid() bool {
+	u.ValidatePresence("name", u.Name)
+	return u.IsValid()
+}
+
+func TestLoadAndValidate(t *testing.T) {
+	req := newRequest("POST", "http://localhost/users", `{"name":""}`)
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	u := testUser{}
+	assertEqual(t, false, c.LoadAndValidate(&u))
+	assertEqual(t, 422, w.Code)
+	assertEqual(t, `{"errors":{"name":["can't be blank"]}}`, w.Body.String())
+
+	req = newRequest("POST", "http://localhost/users", `{"name":"John"}`)
+	w = newRecorder()
+	c.init(w, req, params{})
+	u = testUser{}
+	assertEqual(t, true, c.LoadAndValidate(&u))
+	assertEqual(t, "John", u.Name)
+
+	req = newRequest("POST", "http://localhost/users", `{"name":`)
+	w = newRecorder()
+	c.init(w, req, params{})
+	assertEqual(t, false, c.LoadAndValidate(&u))
+	assertEqual(t, 400, w.Code)
+}
+
+func TestRenderModelErrorsProblem(t *testing.T) {
+	req := newRequest("POST", "http://localhost/users", `{}`)
+	w := newRecorder()
+	c := Ctx{router: &Router{ProblemJSON: true}}
+	c.init(w, req, params{})
+	u := testUser{}
+	u.Valid()
+	c.RenderModelErrors(&u)
+	assertEqual(t, 422, w.Code)
+	assertEqual(t, `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"validation failed","instance":"/users","errors":{"name":["can't be blank"]}}`, w.Body.String())
+}
+
+func TestModelValidateRange(t *testing.T) {
+	m.ResetErrors()
+
+	ValidateRange(&m, "Age", 0, Inclusive(0), Unbounded[int]())
+	assertEqual(t, m.IsValid(), true)
+
+	ValidateRange(&m, "Age", -5, Inclusive(-10), Inclusive(-1))
+	assertEqual(t, m.IsValid(), true)
+
+	ValidateRange(&m, "Age", 0, Exclusive(0), Unbounded[int]())
+	assertEqual(t, m.IsValid(), false)
+	assertEqual(t, []ValidationError{{Code: "greater_than", Params: JSON{"count": 0}, Message: "must be greater than 0"}}, m.GetErrorDetails()["Age"])
+
+	m.ResetErrors()
+
+	ValidateRange(&m, "Temp", 1.5, Unbounded[float64](), Exclusive(1.5))
+	assertEqual(t, []string{"must be less than 1.5"}, m.GetErrors()["Temp"])
+}
+
+func TestModelValidateIntZero(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateInt("Name", -1, 0, -1) // min 0
+	assertEqual(t, []string{"must be greater than or equal to 0"}, m.GetErrors()["Name"])
+}
+
+func TestModelSetErrors(t *testing.T) {
+	m.ResetErrors()
+
+	m.SetErrors(modelErrors{"Name": {"is taken"}})
+	assertEqual(t, []ValidationError{{Message: "is taken"}}, m.GetErrorDetails()["Name"])
+}
+
+// plainModel implements BaseModel without ModelBase
+type plainModel struct {
+	Name   string `json:"name" validate:"required"`
+	Age    int    `json:"age"`
+	errors modelErrors
+}
+
+func (p *plainModel) Valid() bool {
+	Validate(p)
+	ValidateRange(p, "age", p.Age, Inclusive(18), Unbounded[int]())
+	return len(p.errors) == 0
+}
+func (p *plainModel) AddError(f, s string) {
+	if p.errors == nil {
+		p.errors = modelErrors{}
+	}
+	p.errors[f] = append(p.errors[f], s)
+}
+func (p *plainModel) SetErrors(e modelErrors) { p.errors = e }
+func (p *plainModel) GetErrors() modelErrors  { return p.errors }
+func (p *plainModel) ResetErrors()            { p.errors = nil }
+
+func TestPlainBaseModel(t *testing.T) {
+	var m BaseModel = &plainModel{Age: 10}
+	assertEqual(t, false, m.Valid())
+	assertEqual(t, []string{"can't be blank"}, m.GetErrors()["name"])
+	assertEqual(t, []string{"must be greater than or equal to 18"}, m.GetErrors()["age"])
+}
@@ -0,0 +1,142 @@
+package flash2
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// LoadCertPool returns certificate pool with PEM certificates from files
+//
+//	pool, err := flash2.LoadCertPool("ca.pem")
+//	r.TLS.ClientCAs = pool
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("flash2: no certificates found in " + f)
+		}
+	}
+	return pool, nil
+}
+
+// PeerCertificate returns verified client certificate.
+// Returns nil if client certificate was not provided or not verified.
+func (c *Ctx) PeerCertificate() *x509.Certificate {
+	if c.Req.TLS == nil || len(c.Req.TLS.VerifiedChains) == 0 || len(c.Req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return c.Req.TLS.VerifiedChains[0][0]
+}
+
+// PeerSubject returns verified client certificate subject,
+// e.g. "CN=billing,O=Example"
+func (c *Ctx) PeerSubject() string {
+	if cert := c.PeerCertificate(); cert != nil {
+		return cert.Subject.String()
+	}
+	return ""
+}
+
+// PeerSANs returns verified client certificate subject alternative names:
+// DNS names, emails, IP addresses and URIs
+func (c *Ctx) PeerSANs() []string {
+	cert := c.PeerCertificate()
+	if cert == nil {
+		return nil
+	}
+	var res []string
+	res = append(res, cert.DNSNames...)
+	res = append(res, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		res = append(res, ip.String())
+	}
+	for _, u := range cert.URIs {
+		res = append(res, u.String())
+	}
+	return res
+}
+
+// PeerFingerprint returns hex encoded SHA-256 fingerprint of verified
+// client certificate
+func (c *Ctx) PeerFingerprint() string {
+	cert := c.PeerCertificate()
+	if cert == nil {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// RequireSAN returns middleware allowing requests with verified client
+// certificate having one of allowed subject alternative names.
+// Each pattern is matched against its own SAN type: "scheme://..." against
+// URIs, "user@host" against emails, IP addresses against IPs and other
+// patterns against DNS names. "*.example.com" matches any single label
+// DNS subdomain. Other requests get 403 response.
+//
+//	r.Get("/internal", handler, flash2.RequireSAN("billing.svc.local", "spiffe://example.org/billing"))
+func RequireSAN(allowed ...string) MWFunc {
+	return func(c *Ctx) bool {
+		if cert := c.PeerCertificate(); cert != nil {
+			for _, a := range allowed {
+				if matchSAN(a, cert) {
+					return true
+				}
+			}
+		}
+		c.RenderJSONError(http.StatusForbidden, "forbidden")
+		return false
+	}
+}
+
+// matchSAN returns true if certificate has SAN of pattern type matching pattern
+func matchSAN(pattern string, cert *x509.Certificate) bool {
+	switch {
+	case strings.Contains(pattern, "://"):
+		for _, u := range cert.URIs {
+			if u.String() == pattern {
+				return true
+			}
+		}
+	case strings.Contains(pattern, "@"):
+		for _, e := range cert.EmailAddresses {
+			if strings.EqualFold(e, pattern) {
+				return true
+			}
+		}
+	case net.ParseIP(pattern) != nil:
+		ip := net.ParseIP(pattern)
+		for _, a := range cert.IPAddresses {
+			if a.Equal(ip) {
+				return true
+			}
+		}
+	default:
+		for _, d := range cert.DNSNames {
+			if matchDNS(pattern, d) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchDNS returns true if DNS name matches pattern,
+// "*.example.com" matches single label subdomains
+func matchDNS(pattern, name string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		i := strings.IndexByte(name, '.')
+		return i > 0 && strings.EqualFold(name[i:], pattern[1:])
+	}
+	return strings.EqualFold(pattern, name)
+}
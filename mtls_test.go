@@ -0,0 +1,145 @@
+package flash2
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClientCert returns self-signed client certificate
+func testClientCert(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertNil(t, err)
+	u, _ := url.Parse("spiffe://example.org/billing")
+	tpl := &x509.Certificate{
+		SerialNumber:   big.NewInt(1),
+		Subject:        pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		DNSNames:       []string{"billing.svc.local"},
+		EmailAddresses: []string{"ops@example.org"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{u},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		IsCA:           true,
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	assertNil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assertNil(t, err)
+	return cert
+}
+
+func TestCtxPeerCertificate(t *testing.T) {
+	cert := testClientCert(t)
+	r := NewRouter()
+	var subject, fingerprint string
+	var sans []string
+	r.Get("/whoami", func(c *Ctx) {
+		subject, sans, fingerprint = c.PeerSubject(), c.PeerSANs(), c.PeerFingerprint()
+	})
+
+	req := newRequest("GET", "http://localhost/whoami", "")
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	r.ServeHTTP(newRecorder(), req)
+	sum := sha256.Sum256(cert.Raw)
+	assertEqual(t, "CN=billing,O=Example", subject)
+	assertEqual(t, []string{"billing.svc.local", "ops@example.org", "10.0.0.1", "spiffe://example.org/billing"}, sans)
+	assertEqual(t, hex.EncodeToString(sum[:]), fingerprint)
+
+	// unverified certificates are ignored
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	r.ServeHTTP(newRecorder(), req)
+	assertEqual(t, "", subject)
+	assertEqual(t, 0, len(sans))
+	assertEqual(t, "", fingerprint)
+}
+
+func TestRequireSAN(t *testing.T) {
+	cert := testClientCert(t)
+	r := NewRouter()
+	r.Get("/billing", func(c *Ctx) { c.RenderString(200, "ok") }, RequireSAN("*.svc.local"))
+	r.Get("/admin", func(c *Ctx) { c.RenderString(200, "ok") }, RequireSAN("admin.svc.local"))
+
+	req := newRequest("GET", "http://localhost/billing", "")
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+
+	req.URL.Path = "/admin"
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 403, w.Code)
+
+	req = newRequest("GET", "http://localhost/billing", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 403, w.Code)
+
+	assertEqual(t, true, matchDNS("*.svc.local", "billing.SVC.local"))
+	assertEqual(t, false, matchDNS("*.svc.local", "a.b.svc.local"))
+	assertEqual(t, false, matchDNS("*.svc.local", "svc.local"))
+}
+
+func TestMatchSANTypes(t *testing.T) {
+	u, _ := url.Parse("spiffe://evil.example.com")
+	evil := &x509.Certificate{
+		EmailAddresses: []string{"mallory@evil.example.com"},
+		URIs:           []*url.URL{u},
+	}
+	assertEqual(t, false, matchSAN("*.example.com", evil))
+	assertEqual(t, false, matchSAN("evil.example.com", evil))
+
+	cert := testClientCert(t)
+	assertEqual(t, true, matchSAN("*.svc.local", cert))
+	assertEqual(t, true, matchSAN("billing.svc.local", cert))
+	assertEqual(t, true, matchSAN("ops@example.org", cert))
+	assertEqual(t, true, matchSAN("10.0.0.1", cert))
+	assertEqual(t, true, matchSAN("spiffe://example.org/billing", cert))
+	assertEqual(t, false, matchSAN("spiffe://example.org/admin", cert))
+	// DNS pattern is not matched against other SAN types
+	assertEqual(t, false, matchSAN("example.org", cert))
+
+	dnsOnly := &x509.Certificate{DNSNames: []string{"spiffe://example.org/billing", "10.0.0.1"}}
+	assertEqual(t, false, matchSAN("spiffe://example.org/billing", dnsOnly))
+	assertEqual(t, false, matchSAN("10.0.0.1", dnsOnly))
+}
+
+func TestClientAuthConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, 1)
+	caFile := filepath.Join(dir, "ca.pem")
+	assertNil(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testClientCert(t).Raw}), 0600))
+
+	pool, err := LoadCertPool(caFile)
+	assertNil(t, err)
+	_, err = LoadCertPool(keyFile)
+	assertEqual(t, true, strings.Contains(err.Error(), "no certificates"))
+
+	r := NewRouter()
+	r.TLS.ClientCAs = pool
+	cfg, _, err := r.tlsConfig(certFile, keyFile)
+	assertNil(t, err)
+	assertEqual(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+
+	r.TLS.ClientAuth = tls.VerifyClientCertIfGiven
+	cfg, _, err = r.tlsConfig(certFile, keyFile)
+	assertNil(t, err)
+	assertEqual(t, tls.VerifyClientCertIfGiven, cfg.ClientAuth)
+}
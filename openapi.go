@@ -0,0 +1,206 @@
+package flash2
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// OpenAPIInfo is info object of OpenAPI document
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operation describes route for OpenAPI document.
+// Request and Response are model values used to generate JSON schemas
+//
+//	r.Describe("POST", "/pages", flash2.Operation{
+//		Summary:  "create page",
+//		Request:  Page{},
+//		Response: Page{},
+//	})
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is request body model
+	Request interface{}
+	// Response is 200 response body model
+	Response interface{}
+	// Responses are response body models by status code, nil for empty body
+	Responses map[int]interface{}
+}
+
+// routeOp is registered route information
+type routeOp struct {
+	method string
+	path   string
+	action CtrAction
+}
+
+// addOperation records route for OpenAPI document
+func (r *Router) addOperation(method, path string, a CtrAction) {
+	r.ops = append(r.ops, routeOp{method: method, path: path, action: a})
+}
+
+// Describe adds OpenAPI description to route
+func (r *Router) Describe(method, path string, op Operation) {
+	r.NewRoute("").Describe(method, path, op)
+}
+
+// Describe adds OpenAPI description to route
+func (r *Route) Describe(method, path string, op Operation) {
+	if r.router.docs == nil {
+		r.router.docs = make(map[string]Operation)
+	}
+	r.router.docs[method+" "+cleanPath(r.prefix+path)] = op
+}
+
+// OpenAPI returns OpenAPI 3.1 document built from registered routes.
+// Route params ":id" and "@name" become "{id}" and "{name}",
+// controller and action names become operationId and tags.
+func (r *Router) OpenAPI(info OpenAPIInfo) JSON {
+	paths := JSON{}
+	ids := map[string]bool{}
+	for _, o := range r.ops {
+		if o.path == r.openAPIPath {
+			continue
+		}
+		tpl, pars := openAPIPath(o.path)
+		item, ok := paths[tpl].(JSON)
+		if !ok {
+			item = JSON{}
+			paths[tpl] = item
+		}
+		method := strings.ToLower(o.method)
+		if _, ok := item[method]; ok {
+			continue
+		}
+		item[method] = r.operation(o, pars, ids)
+	}
+	return JSON{
+		"openapi": "3.1.0",
+		"info":    info,
+		"paths":   paths,
+	}
+}
+
+// ServeOpenAPI serves OpenAPI document on GET path
+//
+//	r.ServeOpenAPI("/openapi.json", flash2.OpenAPIInfo{Title: "API", Version: "1.0"})
+func (r *Router) ServeOpenAPI(path string, info OpenAPIInfo) {
+	r.openAPIPath = cleanPath(path)
+	r.Get(path, func(c *Ctx) {
+		c.RenderJSON(http.StatusOK, r.OpenAPI(info))
+	})
+}
+
+// operation returns OpenAPI operation object for route
+func (r *Router) operation(o routeOp, pars []string, ids map[string]bool) JSON {
+	doc := r.docs[o.method+" "+o.path]
+	op := JSON{"operationId": operationID(o, ids)}
+
+	tags := doc.Tags
+	if len(tags) == 0 && o.action.Controller != "" {
+		tags = []string{o.action.Controller}
+	}
+	if len(tags) > 0 {
+		op["tags"] = tags
+	}
+	if doc.Summary != "" {
+		op["summary"] = doc.Summary
+	}
+	if doc.Description != "" {
+		op["description"] = doc.Description
+	}
+
+	if len(pars) > 0 {
+		var list []JSON
+		for _, p := range pars {
+			list = append(list, JSON{
+				"name":     p,
+				"in":       "path",
+				"required": true,
+				"schema":   JSON{"type": "string"},
+			})
+		}
+		op["parameters"] = list
+	}
+
+	if doc.Request != nil {
+		op["requestBody"] = JSON{
+			"required": true,
+			"content":  jsonContent(doc.Request),
+		}
+	}
+
+	responses := JSON{}
+	if doc.Response != nil {
+		responses["200"] = JSON{"description": http.StatusText(200), "content": jsonContent(doc.Response)}
+	}
+	for code, m := range doc.Responses {
+		resp := JSON{"description": http.StatusText(code)}
+		if m != nil {
+			resp["content"] = jsonContent(m)
+		}
+		responses[strconv.Itoa(code)] = resp
+	}
+	if len(responses) == 0 {
+		responses["default"] = JSON{"description": "response"}
+	}
+	op["responses"] = responses
+	return op
+}
+
+// jsonContent returns OpenAPI content object with model schema
+func jsonContent(m interface{}) JSON {
+	s := typeSchema(reflect.TypeOf(m), map[reflect.Type]bool{})
+	return JSON{"application/json": JSON{"schema": s}}
+}
+
+// operationID returns unique operationId for route
+func operationID(o routeOp, ids map[string]bool) string {
+	id := o.action.Name
+	if o.action.Controller != "" {
+		id = o.action.Controller + "." + id
+	}
+	if id == "" {
+		// GET /pages/:id becomes getPagesId
+		id = strings.ToLower(o.method)
+		for _, w := range strings.FieldsFunc(o.path, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			id += strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	if ids[id] {
+		id += "_" + strings.ToLower(o.method)
+	}
+	base := id
+	for i := 2; ids[id]; i++ {
+		id = base + "_" + strconv.Itoa(i)
+	}
+	ids[id] = true
+	return id
+}
+
+// openAPIPath converts route template to OpenAPI path template
+// returning path params names
+func openAPIPath(path string) (string, []string) {
+	var pars []string
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		if name, param := keyParams(p); name != p {
+			parts[i] = "{" + param + "}"
+			pars = append(pars, param)
+		}
+	}
+	return strings.Join(parts, "/"), pars
+}
@@ -0,0 +1,49 @@
+package flash2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPIPath(t *testing.T) {
+	p, pars := openAPIPath("/api/pages/:id/files/@name")
+	assertEqual(t, "/api/pages/{id}/files/{name}", p)
+	assertEqual(t, []string{"id", "name"}, pars)
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/api").Controller("/pages", C{})
+	r.Get("/tags/:id", RouteHandler)
+	r.Describe("GET", "/tags/:id", Operation{Summary: "show tag", Response: sTag{}, Responses: map[int]interface{}{404: nil}})
+	r.ServeOpenAPI("/openapi.json", OpenAPIInfo{Title: "API", Version: "1.0"})
+
+	req := newRequest("GET", "http://localhost/openapi.json", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assertEqual(t, "3.1.0", doc.OpenAPI)
+	assertNil(t, doc.Paths["/openapi.json"])
+
+	pages := doc.Paths["/api/pages/{id}"]
+	assertEqual(t, 5, len(pages))
+	assertEqual(t, `{"operationId":"C.Show","parameters":[{"in":"path","name":"id","required":true,"schema":{"type":"string"}}],"responses":{"default":{"description":"response"}},"tags":["C"]}`, string(pages["get"]))
+
+	var ops []string
+	for _, m := range []string{"post", "patch", "put"} {
+		var op struct {
+			ID string `json:"operationId"`
+		}
+		json.Unmarshal(pages[m], &op)
+		ops = append(ops, op.ID)
+	}
+	assertEqual(t, []string{"C.Update", "C.Update_patch", "C.Update_put"}, ops)
+
+	assertEqual(t, `{"operationId":"getTagsId","parameters":[{"in":"path","name":"id","required":true,"schema":{"type":"string"}}],"responses":{"200":{"content":{"application/json":{"schema":{"properties":{"name":{"maxLength":10,"minLength":1,"type":"string"}},"required":["name"],"type":"object"}}},"description":"OK"},"404":{"description":"Not Found"}},"summary":"show tag"}`, string(doc.Paths["/tags/{id}"]["get"]))
+}
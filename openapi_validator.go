@@ -0,0 +1,693 @@
+package flash2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIValidator validates requests against OpenAPI 3 document.
+// Set it as Router.Contract to validate path params, query, headers and
+// JSON body of every request before MWFunc chain
+//
+//	v, err := flash2.LoadOpenAPI("./openapi.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.Contract = v
+type OpenAPIValidator struct {
+	// ValidateResponses enables response validation, useful in tests
+	ValidateResponses bool
+	// OnResponseError is called for invalid responses (default: log.Print)
+	OnResponseError func(*http.Request, error)
+	// MaxBodyBytes limits size of validated request bodies,
+	// larger requests get 413 response (default: 1MB)
+	MaxBodyBytes int64
+
+	doc      JSON
+	base     string
+	paths    []specPath
+	patterns map[string]*regexp.Regexp
+}
+
+// defaultMaxBodyBytes is default limit of validated request body size
+const defaultMaxBodyBytes = 1 << 20
+
+// specPath is OpenAPI path template split into segments
+type specPath struct {
+	tpl  string
+	segs []string
+	item JSON
+}
+
+// before returns true if path must be matched before o.
+// Literal segments rank above {param} segments.
+func (sp specPath) before(o specPath) bool {
+	for i := 0; i < len(sp.segs) && i < len(o.segs); i++ {
+		a, b := isTemplateSeg(sp.segs[i]), isTemplateSeg(o.segs[i])
+		if a != b {
+			return b
+		}
+	}
+	return sp.tpl < o.tpl
+}
+
+// isTemplateSeg returns true if path segment is {param}
+func isTemplateSeg(s string) bool {
+	return strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+}
+
+// ResponseValidationError is passed to OnResponseError for invalid responses
+type ResponseValidationError struct {
+	Status int
+	Errors map[string][]string
+}
+
+// Error implements error interface
+func (e *ResponseValidationError) Error() string {
+	var list []string
+	for k, msgs := range e.Errors {
+		list = append(list, k+" "+strings.Join(msgs, ", "))
+	}
+	return fmt.Sprintf("invalid %d response: %s", e.Status, strings.Join(list, "; "))
+}
+
+// LoadOpenAPI loads OpenAPI document from local JSON file
+func LoadOpenAPI(file string) (*OpenAPIValidator, error) {
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("flash2: YAML OpenAPI documents are not supported, convert %s to JSON", file)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewOpenAPIValidator(b)
+}
+
+// NewOpenAPIValidator creates validator from OpenAPI JSON document
+func NewOpenAPIValidator(b []byte) (*OpenAPIValidator, error) {
+	var doc JSON
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("flash2: invalid OpenAPI document: %s", err)
+	}
+	v := &OpenAPIValidator{doc: doc, patterns: map[string]*regexp.Regexp{}}
+	if err := v.compilePatterns(map[string]interface{}(doc)); err != nil {
+		return nil, err
+	}
+
+	if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		if s, ok := servers[0].(map[string]interface{}); ok {
+			if u, err := url.Parse(fmt.Sprint(s["url"])); err == nil {
+				v.base = strings.TrimSuffix(u.Path, "/")
+			}
+		}
+	}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for tpl, item := range paths {
+		if m, ok := item.(map[string]interface{}); ok {
+			v.paths = append(v.paths, specPath{tpl: tpl, segs: strings.Split(strings.Trim(tpl, "/"), "/"), item: m})
+		}
+	}
+	// concrete paths must match before templated ones
+	sort.Slice(v.paths, func(i, j int) bool { return v.paths[i].before(v.paths[j]) })
+	return v, nil
+}
+
+// compilePatterns compiles schema patterns found in document
+func (v *OpenAPIValidator) compilePatterns(i interface{}) error {
+	switch x := i.(type) {
+	case map[string]interface{}:
+		for k, val := range x {
+			if p, ok := val.(string); ok && k == "pattern" {
+				if _, ok := v.patterns[p]; ok {
+					continue
+				}
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return fmt.Errorf("flash2: invalid OpenAPI pattern %q: %s", p, err)
+				}
+				v.patterns[p] = re
+				continue
+			}
+			if err := v.compilePatterns(val); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, val := range x {
+			if err := v.compilePatterns(val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// begin validates request. Returns false if request is invalid and error
+// is rendered. Returned function must be called after handler to validate
+// response, it is nil if response validation is disabled.
+func (v *OpenAPIValidator) begin(c *Ctx) (bool, func()) {
+	item, pathParams := v.match(c.Req.URL.Path)
+	if item == nil {
+		return true, nil
+	}
+	op, _ := item[strings.ToLower(c.Req.Method)].(map[string]interface{})
+	if op == nil {
+		return true, nil
+	}
+
+	e := &ModelBase{}
+	v.validateParams(e, c, pathParams, item["parameters"])
+	v.validateParams(e, c, pathParams, op["parameters"])
+	if err := v.validateBody(e, c, op); err != nil {
+		c.RenderJSONError(http.StatusRequestEntityTooLarge, "request body too large")
+		return false, nil
+	}
+	if !e.IsValid() {
+		c.renderModelErrors(http.StatusBadRequest, e)
+		return false, nil
+	}
+
+	if !v.ValidateResponses {
+		return true, nil
+	}
+	rec := &responseRecorder{ResponseWriter: c.W, code: http.StatusOK}
+	c.W = rec
+	req := c.Req
+	return true, func() {
+		if err := v.validateResponse(op, rec); err != nil {
+			if v.OnResponseError != nil {
+				v.OnResponseError(req, err)
+			} else {
+				log.Print(err)
+			}
+		}
+		rec.flush()
+	}
+}
+
+// match returns OpenAPI path item and path params for URL path
+func (v *OpenAPIValidator) match(p string) (JSON, map[string]string) {
+	if v.base != "" {
+		if p != v.base && !strings.HasPrefix(p, v.base+"/") {
+			return nil, nil
+		}
+		p = p[len(v.base):]
+	}
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	for _, sp := range v.paths {
+		if len(sp.segs) != len(segs) {
+			continue
+		}
+		pars := map[string]string{}
+		for i, s := range sp.segs {
+			if isTemplateSeg(s) {
+				pars[s[1:len(s)-1]], _ = url.PathUnescape(segs[i])
+			} else if s != segs[i] {
+				pars = nil
+				break
+			}
+		}
+		if pars != nil {
+			return sp.item, pars
+		}
+	}
+	return nil, nil
+}
+
+// validateParams validates path, query and header parameters
+func (v *OpenAPIValidator) validateParams(e *ModelBase, c *Ctx, pathParams map[string]string, list interface{}) {
+	params, _ := list.([]interface{})
+	for _, p := range params {
+		par := v.resolve(p)
+		name, _ := par["name"].(string)
+		in, _ := par["in"].(string)
+		required, _ := par["required"].(bool)
+		schema := v.resolve(par["schema"])
+
+		var raw []string
+		switch in {
+		case "path":
+			if s, ok := pathParams[name]; ok {
+				raw = []string{s}
+			}
+		case "query":
+			raw = c.Req.URL.Query()[name]
+		case "header":
+			raw = c.Req.Header.Values(name)
+		default:
+			continue
+		}
+
+		key := in + "." + name
+		if len(raw) == 0 {
+			if required || in == "path" {
+				e.AddErrorCode(key, "blank", nil)
+			}
+			continue
+		}
+		if schema != nil {
+			v.validateSchema(e, key, schema, v.coerceParam(schema, raw))
+		}
+	}
+}
+
+// validateBody validates JSON request body and restores it for handler.
+// Bodies of other media types are not read.
+// Returns error if JSON body is larger than MaxBodyBytes.
+func (v *OpenAPIValidator) validateBody(e *ModelBase, c *Ctx, op JSON) error {
+	body := v.resolve(op["requestBody"])
+	if body == nil {
+		return nil
+	}
+	required, _ := body["required"].(bool)
+	content := v.resolve(body["content"])
+
+	ctype := ""
+	if h := c.Req.Header.Get("Content-Type"); h != "" {
+		ctype, _, _ = mime.ParseMediaType(h)
+	}
+	if ctype != "" && len(content) > 0 && !hasMediaType(content, ctype) {
+		e.AddErrorCode("header.Content-Type", "inclusion", JSON{"list": mediaTypes(content)})
+		return nil
+	}
+	schema := jsonSchemaOf(content)
+	if schema == nil || (ctype != "" && !isJSONMediaType(ctype)) {
+		if required && c.Req.ContentLength == 0 {
+			e.AddErrorCode("body", "blank", nil)
+		}
+		return nil
+	}
+
+	limit := v.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(c.W, c.Req.Body, limit))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return err
+	}
+	c.Req.Body = io.NopCloser(bytes.NewReader(b))
+
+	if len(bytes.TrimSpace(b)) == 0 {
+		if required {
+			e.AddErrorCode("body", "blank", nil)
+		}
+		return nil
+	}
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		e.AddErrorCode("body", "invalid_json", nil)
+		return nil
+	}
+	v.validateSchema(e, "body", v.resolve(schema), val)
+	return nil
+}
+
+// isJSONMediaType returns true for application/json and +json media types
+func isJSONMediaType(ct string) bool {
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// hasMediaType returns true if content object accepts media type,
+// "type/*" and "*/*" ranges are supported
+func hasMediaType(content JSON, ct string) bool {
+	for k := range content {
+		k = strings.ToLower(k)
+		if k == ct || k == "*/*" || (strings.HasSuffix(k, "/*") && strings.HasPrefix(ct, k[:len(k)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaTypes returns sorted media types of content object
+func mediaTypes(content JSON) []string {
+	res := make([]string, 0, len(content))
+	for k := range content {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// validateResponse validates recorded JSON response
+func (v *OpenAPIValidator) validateResponse(op JSON, rec *responseRecorder) error {
+	responses, _ := op["responses"].(map[string]interface{})
+	resp := v.resolve(responses[strconv.Itoa(rec.code)])
+	if resp == nil {
+		resp = v.resolve(responses[strconv.Itoa(rec.code/100)+"XX"])
+	}
+	if resp == nil {
+		resp = v.resolve(responses["default"])
+	}
+	e := &ModelBase{}
+	if resp == nil {
+		e.AddErrorCode("status", "inclusion", nil)
+	} else if schema := jsonSchemaOf(v.resolve(resp["content"])); schema != nil {
+		var val interface{}
+		if err := json.Unmarshal(rec.body(), &val); err != nil {
+			e.AddErrorCode("body", "invalid_json", nil)
+		} else {
+			v.validateSchema(e, "body", v.resolve(schema), val)
+		}
+	}
+	if e.IsValid() {
+		return nil
+	}
+	return &ResponseValidationError{Status: rec.code, Errors: e.GetErrors()}
+}
+
+// resolve returns schema object following local $ref pointers
+func (v *OpenAPIValidator) resolve(i interface{}) JSON {
+	m, _ := i.(map[string]interface{})
+	for n := 0; n < 32 && m != nil; n++ {
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return m
+		}
+		var cur interface{} = map[string]interface{}(v.doc)
+		for _, p := range strings.Split(ref[2:], "/") {
+			p = strings.NewReplacer("~1", "/", "~0", "~").Replace(p)
+			obj, _ := cur.(map[string]interface{})
+			cur = obj[p]
+		}
+		m, _ = cur.(map[string]interface{})
+	}
+	return m
+}
+
+// validateSchema validates value against JSON schema adding errors with key
+func (v *OpenAPIValidator) validateSchema(e *ModelBase, key string, s JSON, val interface{}) {
+	s = v.resolve(map[string]interface{}(s))
+	if s == nil {
+		return
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		v.validateSchema(e, key, v.resolve(sub), val)
+	}
+	if list := schemaList(s["anyOf"]); len(list) > 0 && v.matches(list, val) == 0 {
+		e.AddErrorCode(key, "invalid", nil)
+	}
+	if list := schemaList(s["oneOf"]); len(list) > 0 && v.matches(list, val) != 1 {
+		e.AddErrorCode(key, "invalid", nil)
+	}
+
+	if val == nil {
+		if nullable, _ := s["nullable"].(bool); nullable || typeAllowed(s["type"], "null") || s["type"] == nil {
+			return
+		}
+		e.AddErrorCode(key, "blank", nil)
+		return
+	}
+	if t := jsonType(val); s["type"] != nil && !typeAllowed(s["type"], t) {
+		if !(t == "integer" && typeAllowed(s["type"], "number")) {
+			e.AddErrorCode(key, "invalid_type", JSON{"type": typeName(s["type"])})
+			return
+		}
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok && !inEnum(val, enum) {
+		e.AddErrorCode(key, "inclusion", JSON{"list": enum})
+	}
+
+	switch x := val.(type) {
+	case string:
+		n := len([]rune(x))
+		if min, ok := s["minLength"].(float64); ok && float64(n) < min {
+			e.AddErrorCode(key, "too_short", JSON{"count": min})
+		}
+		if max, ok := s["maxLength"].(float64); ok && float64(n) > max {
+			e.AddErrorCode(key, "too_long", JSON{"count": max})
+		}
+		if p, ok := s["pattern"].(string); ok && v.patterns[p] != nil && !v.patterns[p].MatchString(x) {
+			e.AddErrorCode(key, "invalid", nil)
+		}
+		if f, ok := s["format"].(string); ok && !validFormat(f, x) {
+			e.AddErrorCode(key, "invalid", JSON{"format": f})
+		}
+	case float64:
+		validateNumber(e, key, s, x)
+	case []interface{}:
+		if min, ok := s["minItems"].(float64); ok && float64(len(x)) < min {
+			e.AddErrorCode(key, "too_few", JSON{"count": min})
+		}
+		if max, ok := s["maxItems"].(float64); ok && float64(len(x)) > max {
+			e.AddErrorCode(key, "too_many", JSON{"count": max})
+		}
+		if items := v.resolve(s["items"]); items != nil {
+			for i, item := range x {
+				v.validateSchema(e, fmt.Sprintf("%s[%d]", key, i), items, item)
+			}
+		}
+	case map[string]interface{}:
+		props, _ := s["properties"].(map[string]interface{})
+		for _, r := range schemaList(s["required"]) {
+			name, _ := r.(string)
+			if _, ok := x[name]; !ok {
+				e.AddErrorCode(key+"."+name, "blank", nil)
+			}
+		}
+		for name, pv := range x {
+			if ps := v.resolve(props[name]); ps != nil {
+				v.validateSchema(e, key+"."+name, ps, pv)
+				continue
+			}
+			switch ap := s["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					e.AddErrorCode(key+"."+name, "unknown_property", nil)
+				}
+			case map[string]interface{}:
+				v.validateSchema(e, key+"."+name, v.resolve(ap), pv)
+			}
+		}
+	}
+}
+
+// matches returns number of schemas value is valid against
+func (v *OpenAPIValidator) matches(list []interface{}, val interface{}) int {
+	n := 0
+	for _, sub := range list {
+		e := &ModelBase{}
+		v.validateSchema(e, "", v.resolve(sub), val)
+		if e.IsValid() {
+			n++
+		}
+	}
+	return n
+}
+
+// validateNumber validates numeric constraints
+func validateNumber(e *ModelBase, key string, s JSON, x float64) {
+	if min, ok := s["minimum"].(float64); ok {
+		if ex, _ := s["exclusiveMinimum"].(bool); ex && x <= min {
+			e.AddErrorCode(key, "greater_than", JSON{"count": min})
+		} else if x < min {
+			e.AddErrorCode(key, "greater_than_or_equal_to", JSON{"count": min})
+		}
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && x <= min {
+		e.AddErrorCode(key, "greater_than", JSON{"count": min})
+	}
+	if max, ok := s["maximum"].(float64); ok {
+		if ex, _ := s["exclusiveMaximum"].(bool); ex && x >= max {
+			e.AddErrorCode(key, "less_than", JSON{"count": max})
+		} else if x > max {
+			e.AddErrorCode(key, "less_than_or_equal_to", JSON{"count": max})
+		}
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && x >= max {
+		e.AddErrorCode(key, "less_than", JSON{"count": max})
+	}
+}
+
+// coerceParam converts raw string parameter values to schema type
+func (v *OpenAPIValidator) coerceParam(s JSON, raw []string) interface{} {
+	if typeAllowed(s["type"], "array") {
+		items := v.resolve(s["items"])
+		var res []interface{}
+		for _, r := range raw {
+			for _, p := range strings.Split(r, ",") {
+				res = append(res, coerceValue(items, p))
+			}
+		}
+		return res
+	}
+	return coerceValue(s, raw[0])
+}
+
+// coerceValue converts raw string to schema type if possible
+func coerceValue(s JSON, raw string) interface{} {
+	switch {
+	case typeAllowed(s["type"], "integer"), typeAllowed(s["type"], "number"):
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case typeAllowed(s["type"], "boolean"):
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// jsonSchemaOf returns schema of JSON media type from content object
+func jsonSchemaOf(content JSON) interface{} {
+	for ct, mt := range content {
+		if isJSONMediaType(ct) {
+			if m, ok := mt.(map[string]interface{}); ok {
+				return m["schema"]
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType returns JSON schema type of decoded JSON value
+func jsonType(val interface{}) string {
+	switch x := val.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
+
+// typeAllowed returns true if schema type allows t
+func typeAllowed(st interface{}, t string) bool {
+	switch x := st.(type) {
+	case string:
+		return x == t
+	case []interface{}:
+		for _, v := range x {
+			if v == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns schema type as string for error params
+func typeName(st interface{}) string {
+	if list, ok := st.([]interface{}); ok {
+		var res []string
+		for _, v := range list {
+			res = append(res, fmt.Sprint(v))
+		}
+		return strings.Join(res, " or ")
+	}
+	return fmt.Sprint(st)
+}
+
+// schemaList returns list value of schema keyword
+func schemaList(i interface{}) []interface{} {
+	l, _ := i.([]interface{})
+	return l
+}
+
+// inEnum returns true if val is one of enum values
+func inEnum(val interface{}, enum []interface{}) bool {
+	b, _ := json.Marshal(val)
+	for _, e := range enum {
+		if eb, _ := json.Marshal(e); bytes.Equal(b, eb) {
+			return true
+		}
+	}
+	return false
+}
+
+// validFormat checks string formats known to validator, unknown formats pass
+func validFormat(f, s string) bool {
+	switch f {
+	case "email":
+		return isEmail(s)
+	case "uri":
+		return isURL(s)
+	case "uuid":
+		return uuidRegexp.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}
+	return true
+}
+
+// responseRecorder buffers response for validation
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+// WriteHeader records status code
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+}
+
+// Write buffers response body
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.buf.Write(b)
+}
+
+// body returns recorded response body decoding gzip content
+func (r *responseRecorder) body() []byte {
+	if r.Header().Get("Content-Encoding") != "gzip" {
+		return r.buf.Bytes()
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(r.buf.Bytes()))
+	if err != nil {
+		return nil
+	}
+	b, _ := io.ReadAll(gz)
+	return b
+}
+
+// flush writes recorded response to underlying writer
+func (r *responseRecorder) flush() {
+	r.ResponseWriter.WriteHeader(r.code)
+	r.ResponseWriter.Write(r.buf.Bytes())
+}
@@ -0,0 +1,207 @@
+package flash2
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func contractRouter(t *testing.T) *Router {
+	v, err := LoadOpenAPI("./test/openapi.json")
+	assertNil(t, err)
+	r := NewRouter()
+	r.Contract = v
+	p := r.PathPrefix("/api")
+	p.Get("/pages/:id", func(c *Ctx) {
+		c.RenderJSON(200, JSON{"name": "page " + c.Param("id")})
+	})
+	p.Put("/pages/:id", func(c *Ctx) {
+		var i interface{}
+		c.LoadJSONRequest(&i)
+		c.RenderJSON(200, i)
+	})
+	return r
+}
+
+func TestLoadOpenAPIYAML(t *testing.T) {
+	_, err := LoadOpenAPI("./test/openapi.yaml")
+	assertNotNil(t, err)
+}
+
+func TestContractParams(t *testing.T) {
+	r := contractRouter(t)
+
+	req := newRequest("GET", "http://localhost/api/pages/1?fields=name,content", "")
+	req.Header.Set("X-Request-Id", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, `{"name":"page 1"}`, w.Body.String())
+
+	req = newRequest("GET", "http://localhost/api/pages/0?fields=title", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 400, w.Code)
+	assertEqual(t, `{"errors":{"header.X-Request-Id":["can't be blank"],"path.id":["must be greater than or equal to 1"],"query.fields[0]":["is not included in the list"]}}`, w.Body.String())
+
+	req = newRequest("GET", "http://localhost/api/pages/abc", "")
+	req.Header.Set("X-Request-Id", "1")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, `{"errors":{"header.X-Request-Id":["invalid format"],"path.id":["must be integer"]}}`, w.Body.String())
+}
+
+func TestContractBody(t *testing.T) {
+	r := contractRouter(t)
+
+	req := newRequest("PUT", "http://localhost/api/pages/1", `{"name":"page","tags":["a"]}`)
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, `{"name":"page","tags":["a"]}`, w.Body.String())
+
+	req = newRequest("PUT", "http://localhost/api/pages/1", `{"tags":["a",1,"c"],"visible":"yes","extra":1}`)
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 400, w.Code)
+	assertEqual(t, `{"errors":{"body.extra":["is not allowed"],"body.name":["can't be blank"],"body.tags":["must have at most 2 items"],"body.tags[1]":["must be string"],"body.visible":["must be boolean"]}}`, w.Body.String())
+
+	req = newRequest("PUT", "http://localhost/api/pages/1", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, `{"errors":{"body":["can't be blank"]}}`, w.Body.String())
+}
+
+func TestContractResponses(t *testing.T) {
+	r := contractRouter(t)
+	var errs []error
+	r.Contract.ValidateResponses = true
+	r.Contract.OnResponseError = func(req *http.Request, err error) { errs = append(errs, err) }
+	r.PathPrefix("/api").Get("/pages/:id", func(c *Ctx) {
+		c.RenderJSON(200, JSON{"title": "page"})
+	})
+
+	req := newRequest("GET", "http://localhost/api/pages/1", "")
+	req.Header.Set("X-Request-Id", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, `{"title":"page"}`, w.Body.String())
+	assertEqual(t, 1, len(errs))
+	assertEqual(t, map[string][]string{
+		"body.name":  {"can't be blank"},
+		"body.title": {"is not allowed"},
+	}, errs[0].(*ResponseValidationError).Errors)
+}
+
+func TestContractConcretePathFirst(t *testing.T) {
+	doc := []byte(`{"openapi":"3.1.0","paths":{
+		"/pages/{id}":{"get":{"parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"integer"}}]}},
+		"/pages/search":{"get":{}},
+		"/{section}/search":{"get":{}}
+	}}`)
+	// map order is random, check several loads
+	for i := 0; i < 20; i++ {
+		v, err := NewOpenAPIValidator(doc)
+		assertNil(t, err)
+		_, pars := v.match("/pages/search")
+		assertEqual(t, map[string]string{}, pars)
+	}
+
+	v, _ := NewOpenAPIValidator(doc)
+	r := NewRouter()
+	r.Contract = v
+	r.Get("/pages/:id", func(c *Ctx) { c.RenderString(200, "page") })
+	r.Get("/pages/search", func(c *Ctx) { c.RenderString(200, "search") })
+
+	req := newRequest("GET", "http://localhost/pages/search", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+
+	req = newRequest("GET", "http://localhost/pages/abc", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, `{"errors":{"path.id":["must be integer"]}}`, w.Body.String())
+}
+
+func TestContractBodyLimit(t *testing.T) {
+	r := contractRouter(t)
+	r.Contract.MaxBodyBytes = 16
+
+	req := newRequest("PUT", "http://localhost/api/pages/1", `{"name":"page","tags":["a","b","c"]}`)
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 413, w.Code)
+
+	req = newRequest("PUT", "http://localhost/api/pages/1", `{"name":"page"}`)
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+}
+
+func TestContractInvalidPattern(t *testing.T) {
+	_, err := NewOpenAPIValidator([]byte(`{"openapi":"3.1.0","paths":{"/a":{"get":{"parameters":[
+		{"name":"q","in":"query","schema":{"type":"string","pattern":"[a-"}}
+	]}}}}`))
+	assertNotNil(t, err)
+}
+
+func TestContractNonJSONBody(t *testing.T) {
+	v, err := NewOpenAPIValidator([]byte(`{"openapi":"3.1.0","paths":{
+		"/files":{"post":{"requestBody":{"required":true,"content":{
+			"multipart/form-data":{"schema":{"type":"object"}},
+			"application/json":{"schema":{"type":"object","required":["url"]}}
+		}}}},
+		"/pages":{"post":{"requestBody":{"content":{"application/json":{"schema":{"type":"object"}}}}}}
+	}}`))
+	assertNil(t, err)
+	v.MaxBodyBytes = 1024
+	r := NewRouter()
+	r.Contract = v
+	r.Post("/files", func(c *Ctx) {
+		b, _ := io.ReadAll(c.Req.Body)
+		c.RenderString(200, strconv.Itoa(len(b)))
+	})
+	r.Post("/pages", func(c *Ctx) { c.RenderString(200, "ok") })
+
+	body := "--x\r\nContent-Disposition: form-data; name=\"f\"; filename=\"a.txt\"\r\n\r\n" +
+		strings.Repeat("a", 4096) + "\r\n--x--\r\n"
+	req := newRequest("POST", "http://localhost/files", body)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, strconv.Itoa(len(body)), w.Body.String())
+
+	req = newRequest("POST", "http://localhost/files", `{}`)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, `{"errors":{"body.url":["can't be blank"]}}`, w.Body.String())
+
+	req = newRequest("POST", "http://localhost/pages", "text")
+	req.Header.Set("Content-Type", "text/plain")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 400, w.Code)
+	assertEqual(t, true, strings.Contains(w.Body.String(), "header.Content-Type"))
+}
+
+func TestContractBasePathSegments(t *testing.T) {
+	r := contractRouter(t)
+	r.Get("/apiv2/pages/:id", func(c *Ctx) { c.RenderString(200, "v2") })
+
+	req := newRequest("GET", "http://localhost/apiv2/pages/abc", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, "v2", w.Body.String())
+
+	item, _ := r.Contract.match("/apiv2/pages/1")
+	assertEqual(t, true, item == nil)
+	item, _ = r.Contract.match("/api/pages/1")
+	assertEqual(t, false, item == nil)
+}
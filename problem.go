@@ -0,0 +1,92 @@
+package flash2
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Problem is an error in RFC 7807 Problem Details format.
+// Handlers can render it with Ctx.RenderProblem or return it from
+// their own functions and pass it to Ctx.RenderErr
+//
+//	return flash2.NewProblem(404, "page not found")
+type Problem struct {
+	// Type is URI reference that identifies the problem type (default: "about:blank")
+	Type string `json:"type"`
+	// Title is short human-readable summary of the problem type
+	Title string `json:"title"`
+	// Status is HTTP status code
+	Status int `json:"status"`
+	// Detail is human-readable explanation specific to this occurrence
+	Detail string `json:"detail,omitempty"`
+	// Instance is URI reference that identifies this occurrence
+	Instance string `json:"instance,omitempty"`
+	// Errors is extension member with field validation errors
+	Errors map[string][]string `json:"errors,omitempty"`
+}
+
+// NewProblem creates Problem with status and detail message.
+// Title is set to standard status text.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Error implements error interface
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// RenderProblem rendering Problem to client as application/problem+json.
+// Empty fields are filled with defaults from status and request,
+// p itself is not modified so it can be shared between requests.
+func (c *Ctx) RenderProblem(problem *Problem) {
+	p := *problem
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && c.Req != nil {
+		p.Instance = c.Req.URL.Path
+	}
+	c.renderJSON(p.Status, &p, "application/problem+json; charset=utf-8")
+}
+
+// RenderErr rendering error to client. *Problem errors are rendered with
+// their own status, any other error is rendered as 500.
+//
+//	if err := doSomething(); err != nil {
+//		c.RenderErr(err)
+//		return
+//	}
+func (c *Ctx) RenderErr(err error) {
+	var p *Problem
+	if errors.As(err, &p) {
+		if c.problems() {
+			c.RenderProblem(p)
+		} else if p.Status == 0 {
+			c.RenderJSONError(http.StatusInternalServerError, p.Error())
+		} else {
+			c.RenderJSONError(p.Status, p.Error())
+		}
+		return
+	}
+	c.RenderJSONError(http.StatusInternalServerError, err.Error())
+}
+
+// problems returns true if router renders errors in Problem Details format
+func (c *Ctx) problems() bool {
+	return c.router != nil && c.router.ProblemJSON
+}
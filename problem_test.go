@@ -0,0 +1,59 @@
+package flash2
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderProblem(t *testing.T) {
+	req := newRequest("GET", "http://localhost/pages/1", "{}")
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	c.RenderProblem(&Problem{Status: 404, Detail: "page not found"})
+	assertEqual(t, []string{"application/problem+json; charset=utf-8"}, w.HeaderMap["Content-Type"])
+	assertEqual(t, 404, w.Code)
+	assertEqual(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"page not found","instance":"/pages/1"}`, w.Body.String())
+}
+
+func TestRenderProblemShared(t *testing.T) {
+	errNotFound := NewProblem(404, "not found")
+	for _, p := range []string{"/a/1", "/b/2"} {
+		req := newRequest("GET", "http://localhost"+p, "{}")
+		w := newRecorder()
+		c := Ctx{}
+		c.init(w, req, params{})
+		c.RenderProblem(errNotFound)
+		assertEqual(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"not found","instance":"`+p+`"}`, w.Body.String())
+	}
+	assertEqual(t, "", errNotFound.Instance)
+}
+
+func TestRenderJSONErrorProblem(t *testing.T) {
+	r := NewRouter()
+	r.ProblemJSON = true
+	r.Get("/pages/:id", func(c *Ctx) { c.RenderJSONError(400, "bad id") })
+
+	req := newRequest("GET", "http://localhost/pages/1", "{}")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, []string{"application/problem+json; charset=utf-8"}, w.HeaderMap["Content-Type"])
+	assertEqual(t, 400, w.Code)
+	assertEqual(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"bad id","instance":"/pages/1"}`, w.Body.String())
+}
+
+func TestRenderErr(t *testing.T) {
+	req := newRequest("GET", "http://localhost", "{}")
+	w := newRecorder()
+	c := Ctx{}
+	c.init(w, req, params{})
+	c.RenderErr(NewProblem(409, "already exists"))
+	assertEqual(t, 409, w.Code)
+	assertEqual(t, `{"errors":{"message":["already exists"]}}`, w.Body.String())
+
+	w = newRecorder()
+	c.init(w, req, params{})
+	c.RenderErr(errors.New("failure"))
+	assertEqual(t, 500, w.Code)
+	assertEqual(t, `{"errors":{"message":["failure"]}}`, w.Body.String())
+}
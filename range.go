@@ -0,0 +1,61 @@
+package flash2
+
+// Number is constraint for numeric types supported by ValidateRange
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Bound is lower or upper limit for ValidateRange
+type Bound[T Number] struct {
+	value     T
+	set       bool
+	exclusive bool
+}
+
+// Unbounded returns Bound without limit
+func Unbounded[T Number]() Bound[T] {
+	return Bound[T]{}
+}
+
+// Inclusive returns Bound which accepts value equal to v
+func Inclusive[T Number](v T) Bound[T] {
+	return Bound[T]{value: v, set: true}
+}
+
+// Exclusive returns Bound which rejects value equal to v
+func Exclusive[T Number](v T) Bound[T] {
+	return Bound[T]{value: v, set: true, exclusive: true}
+}
+
+// ValidateRange validates that number is in range between min and max.
+// Errors are added with codes "greater_than_or_equal_to", "greater_than",
+// "less_than_or_equal_to" and "less_than" with "count" param.
+//
+//	flash2.ValidateRange(u, "age", u.Age, flash2.Inclusive(0), flash2.Exclusive(150))
+//	flash2.ValidateRange(u, "temp", u.Temp, flash2.Inclusive(-40.0), flash2.Unbounded[float64]())
+func ValidateRange[T Number](m BaseModel, f string, v T, min, max Bound[T]) {
+	if min.set {
+		if min.exclusive && v <= min.value {
+			addErrorCode(m, f, "greater_than", JSON{"count": min.value})
+		} else if v < min.value {
+			addErrorCode(m, f, "greater_than_or_equal_to", JSON{"count": min.value})
+		}
+	}
+	if max.set {
+		if max.exclusive && v >= max.value {
+			addErrorCode(m, f, "less_than", JSON{"count": max.value})
+		} else if v > max.value {
+			addErrorCode(m, f, "less_than_or_equal_to", JSON{"count": max.value})
+		}
+	}
+}
+
+// legacyBound converts -1 "any" sentinel of ValidateInt and friends to Bound
+func legacyBound[T int | int64 | float32 | float64](v T) Bound[T] {
+	if v == -1 {
+		return Unbounded[T]()
+	}
+	return Inclusive(v)
+}
@@ -0,0 +1,43 @@
+package flash2
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// regexpCacheSize is maximum number of compiled patterns kept in cache
+const regexpCacheSize = 256
+
+var regexpCache = struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+// cachedRegexp returns compiled regexp for pattern compiling it once.
+// Panics if pattern is invalid as it is programmer error, not user input error.
+func cachedRegexp(pattern string) *regexp.Regexp {
+	regexpCache.RLock()
+	r := regexpCache.m[pattern]
+	regexpCache.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("flash2: invalid format regexp %q: %s", pattern, err))
+	}
+
+	regexpCache.Lock()
+	if len(regexpCache.m) >= regexpCacheSize {
+		// drop random entry to keep cache bounded
+		for k := range regexpCache.m {
+			delete(regexpCache.m, k)
+			break
+		}
+	}
+	regexpCache.m[pattern] = r
+	regexpCache.Unlock()
+	return r
+}
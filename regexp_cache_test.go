@@ -0,0 +1,35 @@
+package flash2
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestCachedRegexp(t *testing.T) {
+	r := cachedRegexp(`\A\d+\z`)
+	assertEqual(t, true, r == cachedRegexp(`\A\d+\z`))
+
+	for i := 0; i < regexpCacheSize+10; i++ {
+		cachedRegexp(fmt.Sprint(i))
+	}
+	assertEqual(t, regexpCacheSize, len(regexpCache.m))
+}
+
+func TestCachedRegexpInvalid(t *testing.T) {
+	defer func() {
+		assertEqual(t, true, recover() != nil)
+	}()
+	cachedRegexp(`(`)
+}
+
+func TestModelValidateFormatRegexp(t *testing.T) {
+	m.ResetErrors()
+
+	r := regexp.MustCompile(`\A\d{5}\z`)
+	m.ValidateFormatRegexp("Zip", "12345", r)
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateFormatRegexp("Zip", "1234", r)
+	assertEqual(t, m.IsValid(), false)
+}
@@ -1,6 +1,7 @@
 package flash2
 
 import (
+	"io/fs"
 	"net/http"
 	"reflect"
 	"strings"
@@ -23,6 +24,7 @@ func (r *Route) NewRoute(prefix string) *Route {
 func (r *Route) HandleFunc(s string, f func(http.ResponseWriter, *http.Request)) {
 	hf := func(p params) http.Handler { return http.Handler(http.HandlerFunc(f)) }
 	r.router.routes.assign("GET", cleanPath(r.prefix+s), hf)
+	r.router.addOperation("GET", cleanPath(r.prefix+s), CtrAction{})
 }
 
 // Route registers a new route with a matcher for URL path
@@ -52,9 +54,10 @@ func (r *Route) Route(method, path string, f handlerFunc, funcs ...MWFunc) {
 //
 func (r *Route) CtrRoute(method, path string, a CtrAction, funcs []MWFunc) {
 	hf := func(p params) http.Handler {
-		return http.Handler(http.HandlerFunc(handleRoute(&a, p, funcs)))
+		return http.Handler(http.HandlerFunc(handleRoute(r.router, &a, p, funcs)))
 	}
 	r.router.routes.assign(method, cleanPath(r.prefix+path), hf)
+	r.router.addOperation(method, cleanPath(r.prefix+path), a)
 }
 
 // Get shorthand for Route("GET", ...)
@@ -133,7 +136,34 @@ func (r *Route) Controller(path string, controller interface{}, funcs ...MWFunc)
 //  - preferGzip specifying if it should look for gzipped file version
 //
 func (r *Route) FileServer(path string, b ...bool) {
-	hf := func(p params) http.Handler { return fileServer(path, b) }
+	r.fileServer(fileServer(path, b))
+}
+
+// FileServerWithOptions provides static files serving configured with options.
+// Symlinks resolving outside of path are denied
+//
+//	r.PathPrefix("/images").FileServerWithOptions("./public", flash2.FileServerOptions{
+//		Encodings:    []string{"br", "gzip"},
+//		CacheControl: "public, max-age=3600",
+//	})
+func (r *Route) FileServerWithOptions(path string, opts FileServerOptions) {
+	r.fileServer(fileServerDir(path, opts))
+}
+
+// FileServerFS provides static files serving from fs.FS such as embed.FS.
+// Request path is used as file name in fsys
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	r.PathPrefix("/public").FileServerFS(public, flash2.FileServerOptions{DirList: true})
+func (r *Route) FileServerFS(fsys fs.FS, opts FileServerOptions) {
+	r.fileServer(fileServerFS(fsys, opts))
+}
+
+// fileServer assigns file server handler to route prefix
+func (r *Route) fileServer(h http.Handler) {
+	hf := func(p params) http.Handler { return h }
 	r.router.routes.assign("GET", cleanPath(r.prefix+"/@file"), hf)
 }
 
@@ -141,6 +171,7 @@ func (r *Route) FileServer(path string, b ...bool) {
 func (r *Route) Handle(path string, handler http.Handler) {
 	hf := func(p params) http.Handler { return handler }
 	r.router.routes.assign("GET", cleanPath(r.prefix+path), hf)
+	r.router.addOperation("GET", cleanPath(r.prefix+path), CtrAction{})
 }
 
 var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
@@ -2,11 +2,9 @@ package flash2
 
 import (
 	"io"
-	"log"
 	"net/http"
 	"os"
-
-	"github.com/gorilla/handlers"
+	"time"
 )
 
 type handFunc func(params) http.Handler
@@ -18,12 +16,17 @@ func NewRouter() *Router {
 		LogWriter:       os.Stdout,
 		LogHTTP:         true,
 		HandlerNotFound: http.NotFoundHandler(),
+		ServerConfig:    DefaultServerConfig,
 	}
 }
 
 // Router stroring app routes structure
 type Router struct {
 	routes routes
+	// ops and docs are used for OpenAPI document generation
+	ops         []routeOp
+	docs        map[string]Operation
+	openAPIPath string
 
 	// SSL defines server type (default none SSL)
 	SSL bool
@@ -31,9 +34,33 @@ type Router struct {
 	PublicKey string
 	// PrivateKey for SSL processing
 	PrivateKey string
+	// TLS configures SSL server protocol versions, ciphers and
+	// certificate reloading
+	TLS TLSOptions
 	// LogWriter log writer interface
 	LogWriter io.Writer
 	LogHTTP   bool
+	// ProblemJSON renders errors in RFC 7807 application/problem+json
+	// format instead of {"errors":{"message":[...]}} (default: false)
+	ProblemJSON bool
+	// NestedErrors renders nested model errors as nested objects instead
+	// of "address.city" keys (default: false)
+	NestedErrors bool
+	// Contract validates requests against OpenAPI document before
+	// middleware functions (default: none)
+	Contract *OpenAPIValidator
+	// Translator translates error messages to request locale (default: none)
+	Translator Translator
+	// DefaultLocale is used when request locale is not supported (default: "en")
+	DefaultLocale string
+	// ServerConfig configures http.Server started by Serve
+	// (default: DefaultServerConfig)
+	ServerConfig ServerConfig
+	// ShutdownTimeout is time given to in-flight requests to finish
+	// on shutdown (default: 30s)
+	ShutdownTimeout time.Duration
+	// shutdownHooks are functions called after server shutdown
+	shutdownHooks []func()
 
 	HandlerNotFound http.Handler
 }
@@ -100,25 +127,3 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		r.HandlerNotFound.ServeHTTP(w, req)
 	}
 }
-
-// Serve starting http server
-func (r *Router) Serve(bind string) {
-	var err error
-	if r.SSL {
-		log.Printf("Starting secure SSL Server on %s", bind)
-		err = http.ListenAndServeTLS(bind, r.PublicKey, r.PrivateKey, r.logHandler())
-	} else {
-		log.Printf("Starting Server on %s", bind)
-		err = http.ListenAndServe(bind, r.logHandler())
-	}
-	if err != nil {
-		log.Fatalf("Server start error: ", err)
-	}
-}
-
-func (r *Router) logHandler() http.Handler {
-	if r.LogHTTP {
-		return handlers.CombinedLoggingHandler(r.LogWriter, r)
-	}
-	return r
-}
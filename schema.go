@@ -0,0 +1,180 @@
+package flash2
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaDraft is JSON Schema dialect of generated schemas
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFormats maps validation tag formats to JSON Schema formats
+var schemaFormats = map[string]string{
+	"email": "email",
+	"url":   "uri",
+	"uuid":  "uuid",
+}
+
+// Schema returns JSON Schema draft 2020-12 for model struct.
+// Property names are taken from json tags and constraints
+// from "validate" tags (see Validate)
+//
+//	s := flash2.Schema(User{})
+//	// {"$schema":"...","type":"object","properties":{"email":{"type":"string","format":"email"}},...}
+func Schema(model interface{}) JSON {
+	s := typeSchema(reflect.TypeOf(model), map[reflect.Type]bool{})
+	s["$schema"] = schemaDraft
+	return s
+}
+
+// typeSchema returns schema for type. seen prevents infinite recursion
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) JSON {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return JSON{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return JSON{"type": "string"}
+	case reflect.Bool:
+		return JSON{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSON{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSON{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return JSON{"type": "string", "contentEncoding": "base64"}
+		}
+		return JSON{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return JSON{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return JSON{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		props := JSON{}
+		var required []string
+		structSchema(t, props, &required, seen)
+		s := JSON{"type": "object", "properties": props}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+	return JSON{}
+}
+
+// structSchema adds struct fields schemas to props
+func structSchema(t reflect.Type, props JSON, required *[]string, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Type == modelBaseType || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Tag.Get("json") == "" {
+			et := sf.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				structSchema(et, props, required, seen)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := fieldName(sf)
+		s := typeSchema(sf.Type, seen)
+		if applySchemaRules(s, sf.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+		props[name] = s
+	}
+}
+
+// applySchemaRules adds validation tag constraints to schema.
+// Returns true if field is required.
+func applySchemaRules(s JSON, tag string) bool {
+	rules := parseRules(tag)
+	for _, r := range rules {
+		if r[0] == "on" {
+			// group rules can't be expressed in single schema
+			return false
+		}
+	}
+
+	required := false
+	for _, r := range rules {
+		switch r[0] {
+		case "required":
+			required = true
+			switch s["type"] {
+			case "string":
+				s["minLength"] = 1
+			case "array":
+				s["minItems"] = 1
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(r[1], 64)
+			if err != nil {
+				continue
+			}
+			switch s["type"] {
+			case "string":
+				s[r[0]+"Length"] = int(n)
+			case "array":
+				s[r[0]+"Items"] = int(n)
+			case "object":
+				s[r[0]+"Properties"] = int(n)
+			case "integer", "number":
+				if r[0] == "min" {
+					s["minimum"] = n
+				} else {
+					s["maximum"] = n
+				}
+			}
+		case "format":
+			if f, ok := schemaFormats[r[1]]; ok {
+				s["format"] = f
+			}
+		case "in":
+			s["enum"] = strings.Split(r[1], "|")
+		}
+	}
+	return required
+}
+
+// Schemas serves JSON Schemas of models by name on GET prefix/:name
+//
+//	r.PathPrefix("/schemas").Schemas(map[string]interface{}{
+//		"user": User{},
+//		"page": Page{},
+//	})
+//	// GET /schemas/user
+func (r *Route) Schemas(models map[string]interface{}) {
+	r.Get("/:name", func(c *Ctx) {
+		name := c.Param("name")
+		m, ok := models[name]
+		if !ok {
+			c.RenderJSONError(http.StatusNotFound, "schema not found")
+			return
+		}
+		s := Schema(m)
+		s["$id"] = c.Req.URL.Path
+		c.renderJSON(http.StatusOK, s, "application/schema+json")
+	})
+}
@@ -0,0 +1,53 @@
+package flash2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type sTag struct {
+	Name string `json:"name" validate:"required,max=10"`
+}
+
+type sUser struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email" validate:"required,format=email"`
+	Age       int       `json:"age" validate:"min=0,max=150"`
+	Role      string    `json:"role" validate:"in=admin|user"`
+	Nick      string    `json:"nick" validate:"on=create,required"`
+	Tags      []sTag    `json:"tags" validate:"max=5"`
+	CreatedAt time.Time `json:"created_at"`
+	Secret    string    `json:"-"`
+	ModelBase
+}
+
+func TestSchema(t *testing.T) {
+	b, _ := json.Marshal(Schema(sUser{}))
+	assertEqual(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema",`+
+		`"properties":{"age":{"maximum":150,"minimum":0,"type":"integer"},`+
+		`"created_at":{"format":"date-time","type":"string"},`+
+		`"email":{"format":"email","minLength":1,"type":"string"},`+
+		`"id":{"type":"integer"},`+
+		`"nick":{"type":"string"},`+
+		`"role":{"enum":["admin","user"],"type":"string"},`+
+		`"tags":{"items":{"properties":{"name":{"maxLength":10,"minLength":1,"type":"string"}},"required":["name"],"type":"object"},"maxItems":5,"type":"array"}},`+
+		`"required":["email"],"type":"object"}`, string(b))
+}
+
+func TestSchemas(t *testing.T) {
+	r := NewRouter()
+	r.PathPrefix("/schemas").Schemas(map[string]interface{}{"tag": sTag{}})
+
+	req := newRequest("GET", "http://localhost/schemas/tag", "")
+	w := newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 200, w.Code)
+	assertEqual(t, []string{"application/schema+json"}, w.HeaderMap["Content-Type"])
+	assertEqual(t, `{"$id":"/schemas/tag","$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"name":{"maxLength":10,"minLength":1,"type":"string"}},"required":["name"],"type":"object"}`, w.Body.String())
+
+	req = newRequest("GET", "http://localhost/schemas/user", "")
+	w = newRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 404, w.Code)
+}
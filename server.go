@@ -0,0 +1,155 @@
+package flash2
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/handlers"
+)
+
+// defaultShutdownTimeout is default time given to requests to finish on shutdown
+const defaultShutdownTimeout = 30 * time.Second
+
+// ServerConfig configures http.Server, zero values mean no limit
+type ServerConfig struct {
+	// ReadTimeout is maximum duration for reading entire request
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout is maximum duration for reading request headers
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout is maximum duration before timing out response writes
+	WriteTimeout time.Duration
+	// IdleTimeout is maximum time to wait for next request on keep-alive connection
+	IdleTimeout time.Duration
+	// MaxHeaderBytes is maximum size of request headers
+	MaxHeaderBytes int
+	// DisableKeepAlives disables HTTP keep-alive connections
+	DisableKeepAlives bool
+	// ErrorLog logs server errors (default: logger writing to Router.LogWriter)
+	ErrorLog *log.Logger
+	// BaseContext returns base context for requests on listener
+	BaseContext func(net.Listener) context.Context
+}
+
+// DefaultServerConfig limits time clients can hold connections
+var DefaultServerConfig = ServerConfig{
+	ReadTimeout:       30 * time.Second,
+	ReadHeaderTimeout: 10 * time.Second,
+	IdleTimeout:       120 * time.Second,
+	MaxHeaderBytes:    1 << 20,
+}
+
+// Serve starts http server, or https server if SSL is set, and blocks
+// until it fails or SIGINT/SIGTERM is received. On signal in-flight
+// requests are drained and OnShutdown hooks are called.
+func (r *Router) Serve(bind string) error {
+	ctx, stop := signalContext()
+	defer stop()
+	return r.ServeContext(ctx, bind)
+}
+
+// ServeTLS starts https server with certificate and key files and blocks
+// until it fails or SIGINT/SIGTERM is received.
+func (r *Router) ServeTLS(bind, certFile, keyFile string) error {
+	ctx, stop := signalContext()
+	defer stop()
+	return r.serve(ctx, bind, true, certFile, keyFile)
+}
+
+// signalContext returns context done on SIGINT/SIGTERM. Signal handling
+// is restored once it is done, so second signal kills draining process.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// ServeContext starts server like Serve and shuts it down gracefully
+// when ctx is done. Returns nil after graceful shutdown.
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	go func() {
+//		if err := r.ServeContext(ctx, ":8080"); err != nil {
+//			log.Println(err)
+//		}
+//	}()
+func (r *Router) ServeContext(ctx context.Context, bind string) error {
+	return r.serve(ctx, bind, r.SSL, r.PublicKey, r.PrivateKey)
+}
+
+// OnShutdown registers function called after server shutdown
+func (r *Router) OnShutdown(f func()) {
+	r.shutdownHooks = append(r.shutdownHooks, f)
+}
+
+// serve runs server until it fails or ctx is done
+func (r *Router) serve(ctx context.Context, bind string, secure bool, certFile, keyFile string) error {
+	return r.serveListeners(ctx, certFile, keyFile, []Listener{{Addr: bind, TLS: secure}})
+}
+
+// server returns http.Server configured with ServerConfig
+func (r *Router) server(bind string) *http.Server {
+	cfg := r.ServerConfig
+	srv := &http.Server{
+		Addr:              bind,
+		Handler:           r.logHandler(r),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          r.errorLog(),
+		BaseContext:       cfg.BaseContext,
+	}
+	if cfg.DisableKeepAlives {
+		srv.SetKeepAlivesEnabled(false)
+	}
+	return srv
+}
+
+// shutdown gracefully stops servers waiting for in-flight requests
+// up to ShutdownTimeout and calls OnShutdown hooks
+func (r *Router) shutdown(servers ...*http.Server) error {
+	timeout := r.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+			srv.Close()
+		}
+	}
+	for _, f := range r.shutdownHooks {
+		f()
+	}
+	return errors.Join(errs...)
+}
+
+// errorLog returns server error logger
+func (r *Router) errorLog() *log.Logger {
+	if r.ServerConfig.ErrorLog == nil && r.LogWriter != nil {
+		return log.New(r.LogWriter, "http: ", log.LstdFlags)
+	}
+	return r.ServerConfig.ErrorLog
+}
+
+func (r *Router) logHandler(h http.Handler) http.Handler {
+	if r.LogHTTP {
+		return handlers.CombinedLoggingHandler(r.LogWriter, h)
+	}
+	return h
+}
@@ -0,0 +1,146 @@
+package flash2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeAddr returns free local TCP address
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("network is not available")
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// waitServer waits until server accepts connections
+func waitServer(t *testing.T, addr string) {
+	for i := 0; i < 100; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			c.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server is not started")
+}
+
+func TestServeContextShutdown(t *testing.T) {
+	addr := freeAddr(t)
+	r := NewRouter()
+	r.LogHTTP = false
+	started := make(chan bool)
+	r.Get("/slow", func(c *Ctx) {
+		started <- true
+		time.Sleep(100 * time.Millisecond)
+		c.RenderString(200, "done")
+	})
+	hooks := 0
+	r.OnShutdown(func() { hooks++ })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- r.ServeContext(ctx, addr) }()
+	waitServer(t, addr)
+
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		body <- string(b)
+	}()
+	<-started
+	cancel()
+
+	assertEqual(t, "done", <-body)
+	assertNil(t, <-errc)
+	assertEqual(t, 1, hooks)
+}
+
+func TestServeContextTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	r := NewRouter()
+	r.LogHTTP = false
+	r.ShutdownTimeout = 10 * time.Millisecond
+	started := make(chan bool)
+	r.Get("/slow", func(c *Ctx) {
+		started <- true
+		time.Sleep(300 * time.Millisecond)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- r.ServeContext(ctx, addr) }()
+	waitServer(t, addr)
+
+	go http.Get("http://" + addr + "/slow")
+	<-started
+	cancel()
+	assertEqual(t, true, errors.Is(<-errc, context.DeadlineExceeded))
+}
+
+func TestServeError(t *testing.T) {
+	addr := freeAddr(t)
+	ln, err := net.Listen("tcp", addr)
+	assertNil(t, err)
+	defer ln.Close()
+
+	r := NewRouter()
+	assertNotNil(t, r.ServeContext(context.Background(), addr))
+}
+
+type testCtxKey string
+
+func TestServerConfig(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRouter()
+	r.LogWriter = &buf
+	assertEqual(t, 10*time.Second, r.server(":80").ReadHeaderTimeout)
+
+	r.ServerConfig.WriteTimeout = time.Minute
+	r.ServerConfig.MaxHeaderBytes = 4096
+	r.ServerConfig.DisableKeepAlives = true
+	r.ServerConfig.BaseContext = func(net.Listener) context.Context {
+		return context.WithValue(context.Background(), testCtxKey("app"), "test")
+	}
+	srv := r.server(":80")
+	assertEqual(t, ":80", srv.Addr)
+	assertEqual(t, time.Minute, srv.WriteTimeout)
+	assertEqual(t, 4096, srv.MaxHeaderBytes)
+	assertEqual(t, "test", srv.BaseContext(nil).Value(testCtxKey("app")))
+
+	srv.ErrorLog.Print("tls handshake error")
+	assertEqual(t, true, strings.Contains(buf.String(), "tls handshake error"))
+}
+
+func TestServerDisableKeepAlives(t *testing.T) {
+	addr := freeAddr(t)
+	r := NewRouter()
+	r.LogHTTP = false
+	r.ServerConfig.DisableKeepAlives = true
+	r.Get("/", func(c *Ctx) { c.RenderString(200, "ok") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.ServeContext(ctx, addr)
+	waitServer(t, addr)
+
+	res, err := http.Get("http://" + addr + "/")
+	assertNil(t, err)
+	res.Body.Close()
+	assertEqual(t, true, res.Close)
+}
@@ -0,0 +1,179 @@
+package flash2
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// TLSOptions configures TLS of SSL servers
+//
+//	r.SSL = true
+//	r.PublicKey, r.PrivateKey = "cert.pem", "key.pem"
+//	r.TLS = flash2.TLSOptions{
+//		MinVersion:     tls.VersionTLS13,
+//		ReloadInterval: time.Minute,
+//		ReloadOnSignal: true,
+//	}
+type TLSOptions struct {
+	// MinVersion is minimum TLS version (default: TLS 1.2)
+	MinVersion uint16
+	// CipherSuites are enabled TLS 1.0-1.2 cipher suites, TLS 1.3 suites
+	// are not configurable (default: Go defaults)
+	CipherSuites []uint16
+	// NextProtos are ALPN protocols (default: "h2", "http/1.1")
+	NextProtos []string
+	// ReloadInterval is certificate files modification check interval,
+	// zero disables polling (default: 0)
+	ReloadInterval time.Duration
+	// ReloadOnSignal reloads certificate files on SIGHUP (default: false)
+	ReloadOnSignal bool
+	// ClientCAs are certificate authorities verifying client certificates
+	// (default: none)
+	ClientCAs *x509.CertPool
+	// ClientAuth is client certificate verification mode
+	// (default: tls.RequireAndVerifyClientCert if ClientCAs is set)
+	ClientAuth tls.ClientAuthType
+}
+
+// tlsConfig returns server TLS config serving certificate files
+// through reloader
+func (r *Router) tlsConfig(certFile, keyFile string) (*tls.Config, *certReloader, error) {
+	certs, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := r.TLS
+	cfg := &tls.Config{
+		MinVersion:     opts.MinVersion,
+		CipherSuites:   opts.CipherSuites,
+		NextProtos:     opts.NextProtos,
+		GetCertificate: certs.GetCertificate,
+		ClientCAs:      opts.ClientCAs,
+		ClientAuth:     opts.ClientAuth,
+	}
+	if cfg.ClientCAs != nil && cfg.ClientAuth == tls.NoClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if cfg.NextProtos == nil {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	return cfg, certs, nil
+}
+
+// certReloader keeps certificate loaded from files and reloads it
+// when files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// newCertReloader returns reloader with certificate loaded from files
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// reload loads certificate files. Current certificate is kept on error.
+func (c *certReloader) reload() error {
+	mt, err := c.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cert = &cert
+	c.modTime = mt
+	c.mu.Unlock()
+	return nil
+}
+
+// reloadIfChanged loads certificate files if they were modified
+func (c *certReloader) reloadIfChanged() error {
+	mt, err := c.filesModTime()
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	changed := !mt.Equal(c.modTime)
+	c.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	return c.reload()
+}
+
+// filesModTime returns latest modification time of certificate files
+func (c *certReloader) filesModTime() (time.Time, error) {
+	var mt time.Time
+	for _, f := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return mt, err
+		}
+		if info.ModTime().After(mt) {
+			mt = info.ModTime()
+		}
+	}
+	return mt, nil
+}
+
+// watch reloads certificate on file changes or SIGHUP until ctx is done
+func (c *certReloader) watch(ctx context.Context, opts TLSOptions, logger *log.Logger) {
+	var tick <-chan time.Time
+	if opts.ReloadInterval > 0 {
+		t := time.NewTicker(opts.ReloadInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	var hup chan os.Signal
+	if opts.ReloadOnSignal {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+	if tick == nil && hup == nil {
+		return
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			err = c.reloadIfChanged()
+		case <-hup:
+			err = c.reload()
+		}
+		if err != nil && logger != nil {
+			logger.Printf("certificate reload error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package flash2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes self-signed certificate and key files to dir
+func writeTestCert(t *testing.T, dir string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertNil(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	assertNil(t, err)
+	kb, err := x509.MarshalECPrivateKey(key)
+	assertNil(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	assertNil(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assertNil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0600))
+	return certFile, keyFile
+}
+
+// certSerial returns serial number of reloader certificate
+func certSerial(t *testing.T, c *certReloader) int64 {
+	cert, err := c.GetCertificate(nil)
+	assertNil(t, err)
+	x, err := x509.ParseCertificate(cert.Certificate[0])
+	assertNil(t, err)
+	return x.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, 1)
+	c, err := newCertReloader(certFile, keyFile)
+	assertNil(t, err)
+	assertEqual(t, int64(1), certSerial(t, c))
+
+	writeTestCert(t, dir, 2)
+	later := time.Now().Add(time.Minute)
+	assertNil(t, os.Chtimes(certFile, later, later))
+	assertNil(t, c.reloadIfChanged())
+	assertEqual(t, int64(2), certSerial(t, c))
+
+	// broken files keep current certificate
+	assertNil(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	assertNotNil(t, c.reload())
+	assertEqual(t, int64(2), certSerial(t, c))
+
+	_, err = newCertReloader(filepath.Join(dir, "missing.pem"), keyFile)
+	assertNotNil(t, err)
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, 1)
+	c, err := newCertReloader(certFile, keyFile)
+	assertNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.watch(ctx, TLSOptions{ReloadInterval: 5 * time.Millisecond}, nil)
+
+	writeTestCert(t, dir, 3)
+	later := time.Now().Add(time.Minute)
+	assertNil(t, os.Chtimes(keyFile, later, later))
+	for i := 0; i < 100 && certSerial(t, c) != 3; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEqual(t, int64(3), certSerial(t, c))
+}
+
+func TestTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir(), 1)
+	r := NewRouter()
+	cfg, _, err := r.tlsConfig(certFile, keyFile)
+	assertNil(t, err)
+	assertEqual(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+	assertEqual(t, []string{"h2", "http/1.1"}, cfg.NextProtos)
+
+	r.TLS = TLSOptions{
+		MinVersion:   tls.VersionTLS13,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		NextProtos:   []string{"http/1.1"},
+	}
+	cfg, _, err = r.tlsConfig(certFile, keyFile)
+	assertNil(t, err)
+	assertEqual(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assertEqual(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+	assertEqual(t, []string{"http/1.1"}, cfg.NextProtos)
+}
+
+func TestServeTLS(t *testing.T) {
+	addr := freeAddr(t)
+	certFile, keyFile := writeTestCert(t, t.TempDir(), 1)
+	r := NewRouter()
+	r.LogHTTP = false
+	r.LogWriter = io.Discard
+	r.SSL = true
+	r.PublicKey, r.PrivateKey = certFile, keyFile
+	r.Get("/", func(c *Ctx) { c.RenderString(200, "secure") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.ServeContext(ctx, addr)
+	waitServer(t, addr)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	res, err := client.Get("https://" + addr + "/")
+	assertNil(t, err)
+	defer res.Body.Close()
+	assertEqual(t, 200, res.StatusCode)
+	assertEqual(t, uint16(tls.VersionTLS13), res.TLS.Version)
+}
@@ -0,0 +1,314 @@
+package flash2
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]func(string) bool{
+		"email": isEmail,
+		"url":   isURL,
+		"uuid":  uuidRegexp.MatchString,
+		"ip":    func(s string) bool { return net.ParseIP(s) != nil },
+		"cidr":  isCIDR,
+	}
+
+	modelBaseType = reflect.TypeOf(ModelBase{})
+)
+
+// RegisterFormat registers named regex format for "format" validation tag.
+// Panics if pattern is not valid regular expression.
+//
+//	flash2.RegisterFormat("zip", `\A\d{5}\z`)
+func RegisterFormat(name, pattern string) {
+	r := regexp.MustCompile(pattern)
+	formatsMu.Lock()
+	formats[name] = r.MatchString
+	formatsMu.Unlock()
+}
+
+// lookupFormat returns registered format check function
+func lookupFormat(name string) func(string) bool {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return formats[name]
+}
+
+// Validate validates model fields with rules from "validate" struct tags
+// and adds errors to model using json field names as error keys.
+// Nested structs and slices of structs are validated as well and their
+// errors are keyed like "address.city" or "items[2].qty". Nested models
+// implementing BaseModel are skipped as they are validated with their own
+// Valid function by ValidateModel.
+//
+// Supported rules:
+//   - required: value must not be zero value (empty string, empty slice, nil)
+//   - min=N, max=N: minimum and maximum length for strings, slices and maps,
+//     or minimum and maximum value for numbers
+//   - format=name: string must match format: email, url, uuid, ip, cidr
+//     or registered with RegisterFormat
+//   - in=a|b|c: string must be one of the listed values
+//   - on=create|update: field rules apply only in listed validation groups.
+//     Validate skips them, ValidateModel and Ctx.Validate apply them
+//     after Valid (see ValidateContext)
+//
+// Example:
+//
+//	type User struct {
+//		Email    string `json:"email" validate:"required,format=email"`
+//		Password string `json:"password" validate:"required,min=6,max=18"`
+//		flash2.ModelBase
+//	}
+//
+//	func (u *User) Valid() bool {
+//		return flash2.Validate(u)
+//	}
+func Validate(model BaseModel) bool {
+	return ValidateContext(context.Background(), model)
+}
+
+// ValidateContext validates model fields with rules from "validate" struct
+// tags for validation group from context (see WithValidationGroup)
+//
+//	type Page struct {
+//		Name string `json:"name" validate:"on=create,required"`
+//		flash2.ModelBase
+//	}
+//
+//	func (p *Page) ValidWithContext(ctx context.Context) bool {
+//		return flash2.ValidateContext(ctx, p)
+//	}
+func ValidateContext(ctx context.Context, model BaseModel) bool {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() == reflect.Struct {
+		validateStruct(model, v, "", ValidationGroup(ctx), false)
+	}
+	return len(model.GetErrors()) == 0
+}
+
+// validateGroupRules applies only tag rules restricted with "on=" to group
+func validateGroupRules(model BaseModel, group string) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() == reflect.Struct {
+		validateStruct(model, v, "", group, true)
+	}
+}
+
+// validateStruct validates struct fields adding errors prefixed with prefix.
+// If groupOnly is set only fields with "on=" rule are validated.
+func validateStruct(m BaseModel, v reflect.Value, prefix, group string, groupOnly bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		if sf.Type == modelBaseType {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous && sf.Tag.Get("json") == "" {
+			if fv = reflect.Indirect(fv); fv.Kind() == reflect.Struct {
+				validateStruct(m, fv, prefix, group, groupOnly)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		key := prefix + fieldName(sf)
+		validateField(m, key, fv, sf.Tag.Get("validate"), group, groupOnly)
+	}
+}
+
+// validateField applies tag rules to the field and descends into nested values
+func validateField(m BaseModel, key string, v reflect.Value, tag, group string, groupOnly bool) {
+	rules := parseRules(tag)
+	grouped := false
+	for _, r := range rules {
+		if r[0] != "on" {
+			continue
+		}
+		grouped = true
+		if !inList(group, strings.Split(r[1], "|")) {
+			rules = nil
+			break
+		}
+	}
+	if groupOnly && !grouped {
+		rules = nil
+	}
+	for _, r := range rules {
+		if r[0] != "on" && !applyRule(m, key, v, r[0], r[1]) {
+			break
+		}
+	}
+
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		if !isModel(v) {
+			validateStruct(m, v, key+".", group, groupOnly)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if e := reflect.Indirect(v.Index(i)); e.Kind() == reflect.Struct && !isModel(e) {
+				validateStruct(m, e, fmt.Sprintf("%s[%d].", key, i), group, groupOnly)
+			}
+		}
+	}
+}
+
+// parseRules splits validation tag into rule name and argument pairs
+func parseRules(tag string) [][2]string {
+	if tag == "" {
+		return nil
+	}
+	var res [][2]string
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		res = append(res, [2]string{name, arg})
+	}
+	return res
+}
+
+// applyRule validates value with single rule. Returns false if
+// further rules should be skipped for the field.
+func applyRule(m BaseModel, key string, v reflect.Value, name, arg string) bool {
+	switch name {
+	case "required":
+		if isBlank(v) {
+			addErrorCode(m, key, "blank", nil)
+			return false
+		}
+	case "min", "max":
+		v = reflect.Indirect(v)
+		if !v.IsValid() {
+			return true
+		}
+		if n, ok := lengthOf(v); ok {
+			lim := ruleInt(name, arg)
+			if name == "min" && n < lim {
+				addErrorCode(m, key, "too_short", JSON{"count": lim})
+			} else if name == "max" && n > lim {
+				addErrorCode(m, key, "too_long", JSON{"count": lim})
+			}
+			return true
+		}
+		f, ok := numberOf(v)
+		if !ok {
+			panic(fmt.Sprintf("flash2: rule %q is not supported for %s", name, v.Type()))
+		}
+		lim := ruleFloat(name, arg)
+		if name == "min" && f < lim {
+			addErrorCode(m, key, "greater_than_or_equal_to", JSON{"count": lim})
+		} else if name == "max" && f > lim {
+			addErrorCode(m, key, "less_than_or_equal_to", JSON{"count": lim})
+		}
+	case "format":
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.String || v.Len() == 0 {
+			return true
+		}
+		match := lookupFormat(arg)
+		if match == nil {
+			panic(fmt.Sprintf("flash2: unknown validation format %q", arg))
+		}
+		if !match(v.String()) {
+			addErrorCode(m, key, "invalid", JSON{"format": arg})
+		}
+	case "in":
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.String || v.Len() == 0 {
+			return true
+		}
+		list := strings.Split(arg, "|")
+		if !inList(v.String(), list) {
+			addErrorCode(m, key, "inclusion", JSON{"list": list})
+		}
+	default:
+		panic(fmt.Sprintf("flash2: unknown validation rule %q", name))
+	}
+	return true
+}
+
+// isModel returns true if struct value implements BaseModel
+func isModel(v reflect.Value) bool {
+	return reflect.PtrTo(v.Type()).Implements(baseModelType)
+}
+
+// fieldName returns json name of struct field
+func fieldName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// isBlank returns true for zero values, empty strings, slices and maps
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()) == 0
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// lengthOf returns length of strings, slices and maps
+func lengthOf(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// numberOf returns numeric value as float64
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// ruleInt parses integer rule argument
+func ruleInt(name, arg string) int {
+	i, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("flash2: invalid %s argument %q", name, arg))
+	}
+	return i
+}
+
+// ruleFloat parses numeric rule argument
+func ruleFloat(name, arg string) float64 {
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("flash2: invalid %s argument %q", name, arg))
+	}
+	return f
+}
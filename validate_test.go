@@ -0,0 +1,87 @@
+package flash2
+
+import "testing"
+
+type vAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type vItem struct {
+	Qty int `json:"qty" validate:"min=1,max=10"`
+}
+
+type vUser struct {
+	Email    string    `json:"email" validate:"required,format=email"`
+	Password string    `json:"password" validate:"min=6,max=18"`
+	Age      int       `json:"age" validate:"min=0"`
+	Address  vAddress  `json:"address"`
+	Items    []vItem   `json:"items" validate:"required"`
+	Note     string    `json:"-" validate:"max=3"`
+	Extra    *vAddress `json:"extra,omitempty"`
+	ModelBase
+}
+
+func TestValidateTags(t *testing.T) {
+	u := vUser{
+		Email:    "john@example.com",
+		Password: "secret",
+		Address:  vAddress{City: "Paris"},
+		Items:    []vItem{{Qty: 1}},
+	}
+	assertEqual(t, true, Validate(&u))
+
+	u = vUser{
+		Email:    "john",
+		Password: "pass",
+		Age:      -1,
+		Items:    []vItem{{Qty: 1}, {Qty: 11}},
+		Note:     "long",
+		Extra:    &vAddress{},
+	}
+	assertEqual(t, false, Validate(&u))
+	assertEqual(t, modelErrors{
+		"email":        {"invalid format"},
+		"password":     {"minimum length is 6"},
+		"age":          {"must be greater than or equal to 0"},
+		"address.city": {"can't be blank"},
+		"items[1].qty": {"must be less than or equal to 10"},
+		"Note":         {"maximum length is 3"},
+		"extra.city":   {"can't be blank"},
+	}, u.GetErrors())
+
+	u.ResetErrors()
+	u.Items = nil
+	Validate(&u)
+	assertEqual(t, []string{"can't be blank"}, u.GetErrors()["items"])
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("zip", `\A\d{5}\z`)
+	type zipModel struct {
+		Zip string `json:"zip" validate:"format=zip"`
+		ModelBase
+	}
+	z := zipModel{Zip: "12345"}
+	assertEqual(t, true, Validate(&z))
+	z.Zip = "123"
+	assertEqual(t, false, Validate(&z))
+}
+
+func TestValidateTagFormats(t *testing.T) {
+	type host struct {
+		URL  string `json:"url" validate:"format=url"`
+		IP   string `json:"ip" validate:"format=ip"`
+		Kind string `json:"kind" validate:"in=web|db"`
+		ModelBase
+	}
+	h := host{URL: "https://example.com", IP: "::1", Kind: "db"}
+	assertEqual(t, true, Validate(&h))
+
+	h = host{URL: "example.com", IP: "1.1.1", Kind: "mail"}
+	assertEqual(t, false, Validate(&h))
+	assertEqual(t, modelErrors{
+		"url":  {"invalid format"},
+		"ip":   {"invalid format"},
+		"kind": {"is not included in the list"},
+	}, h.GetErrors())
+}
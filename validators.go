@@ -0,0 +1,159 @@
+package flash2
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+var uuidRegexp = regexp.MustCompile(`\A[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\z`)
+
+// ValidateEmail validates string is email address. Empty string is skipped
+//
+//	m.ValidateEmail("email", m.Email)
+func (m *ModelBase) ValidateEmail(f, v string) {
+	if v != "" && !isEmail(v) {
+		m.AddErrorCode(f, "invalid_email", nil)
+	}
+}
+
+// ValidateURL validates string is absolute URL. Empty string is skipped
+//
+//	m.ValidateURL("homepage", m.Homepage)
+func (m *ModelBase) ValidateURL(f, v string) {
+	if v != "" && !isURL(v) {
+		m.AddErrorCode(f, "invalid_url", nil)
+	}
+}
+
+// ValidateUUID validates string is UUID. Empty string is skipped
+//
+//	m.ValidateUUID("token", m.Token)
+func (m *ModelBase) ValidateUUID(f, v string) {
+	if v != "" && !uuidRegexp.MatchString(v) {
+		m.AddErrorCode(f, "invalid_uuid", nil)
+	}
+}
+
+// ValidateIP validates string is IPv4 or IPv6 address. Empty string is skipped
+//
+//	m.ValidateIP("ip", m.IP)
+func (m *ModelBase) ValidateIP(f, v string) {
+	if v != "" && net.ParseIP(v) == nil {
+		m.AddErrorCode(f, "invalid_ip", nil)
+	}
+}
+
+// ValidateCIDR validates string is CIDR notation network. Empty string is skipped
+//
+//	m.ValidateCIDR("network", m.Network) // 10.0.0.0/8
+func (m *ModelBase) ValidateCIDR(f, v string) {
+	if v != "" && !isCIDR(v) {
+		m.AddErrorCode(f, "invalid_cidr", nil)
+	}
+}
+
+// ValidateInclusion validates string is one of the list values
+//
+//	m.ValidateInclusion("role", m.Role, "admin", "user")
+func (m *ModelBase) ValidateInclusion(f, v string, list ...string) {
+	if !inList(v, list) {
+		m.AddErrorCode(f, "inclusion", JSON{"list": list})
+	}
+}
+
+// ValidateExclusion validates string is none of the list values
+//
+//	m.ValidateExclusion("login", m.Login, "admin", "root")
+func (m *ModelBase) ValidateExclusion(f, v string, list ...string) {
+	if inList(v, list) {
+		m.AddErrorCode(f, "exclusion", JSON{"list": list})
+	}
+}
+
+// ValidateConfirmation validates value matches its confirmation.
+// Error is added to f + "_confirmation" field
+//
+//	m.ValidateConfirmation("password", m.Password, m.PasswordConfirmation)
+func (m *ModelBase) ValidateConfirmation(f, v, confirmation string) {
+	if v != confirmation {
+		m.AddErrorCode(f+"_confirmation", "confirmation", JSON{"attribute": f})
+	}
+}
+
+// ValidateDateRange validates time is between min and max inclusive.
+// Zero time for any
+//
+//	m.ValidateDateRange("starts_at", m.StartsAt, time.Now(), time.Time{}) // not in the past
+func (m *ModelBase) ValidateDateRange(f string, v, min, max time.Time) {
+	if !min.IsZero() && v.Before(min) {
+		m.AddErrorCode(f, "on_or_after", JSON{"date": min.Format(time.RFC3339)})
+	}
+	if !max.IsZero() && v.After(max) {
+		m.AddErrorCode(f, "on_or_before", JSON{"date": max.Format(time.RFC3339)})
+	}
+}
+
+// ValidateSliceLength validates slice, array or map min, max length. -1 for any
+//
+//	m.ValidateSliceLength("tags", m.Tags, 1, 5)
+func (m *ModelBase) ValidateSliceLength(f string, v interface{}, min, max int) {
+	n, ok := lengthOf(reflect.ValueOf(v))
+	if !ok {
+		panic("flash2: ValidateSliceLength called with " + reflect.TypeOf(v).String())
+	}
+	if min > -1 && n < min {
+		m.AddErrorCode(f, "too_few", JSON{"count": min})
+	}
+	if max > -1 && n > max {
+		m.AddErrorCode(f, "too_many", JSON{"count": max})
+	}
+}
+
+// ValidateUniqueness validates value is unique using exists lookup function.
+// Lookup errors are returned to caller
+//
+//	err := u.ValidateUniqueness("email", u.Email, func(v interface{}) (bool, error) {
+//		return db.UserExists(v.(string))
+//	})
+func (m *ModelBase) ValidateUniqueness(f string, v interface{}, exists func(interface{}) (bool, error)) error {
+	found, err := exists(v)
+	if err != nil {
+		return err
+	}
+	if found {
+		m.AddErrorCode(f, "taken", nil)
+	}
+	return nil
+}
+
+// isEmail returns true if s is plain email address
+func isEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
+
+// isURL returns true if s is absolute URL with host
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// isCIDR returns true if s is CIDR notation network
+func isCIDR(s string) bool {
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+// inList returns true if list contains s
+func inList(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
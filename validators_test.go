@@ -0,0 +1,112 @@
+package flash2
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestModelValidateEmail(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateEmail("Email", "john@example.com")
+	m.ValidateEmail("Email", "")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateEmail("Email", "John <john@example.com>")
+	assertEqual(t, m.IsValid(), false)
+}
+
+func TestModelValidateURL(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateURL("URL", "http://example.com/path")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateURL("URL", "/path")
+	assertEqual(t, m.IsValid(), false)
+}
+
+func TestModelValidateUUID(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateUUID("ID", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateUUID("ID", "6ba7b810-9dad-11d1-80b4")
+	assertEqual(t, m.IsValid(), false)
+}
+
+func TestModelValidateIPAndCIDR(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateIP("IP", "10.0.0.1")
+	m.ValidateIP("IP", "fe80::1")
+	m.ValidateCIDR("Net", "10.0.0.0/8")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateIP("IP", "10.0.0")
+	m.ValidateCIDR("Net", "10.0.0.0")
+	assertEqual(t, []string{"is not a valid IP address"}, m.GetErrors()["IP"])
+	assertEqual(t, []string{"is not a valid CIDR network"}, m.GetErrors()["Net"])
+}
+
+func TestModelValidateInclusionExclusion(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateInclusion("Role", "admin", "admin", "user")
+	m.ValidateExclusion("Login", "john", "admin", "root")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateInclusion("Role", "guest", "admin", "user")
+	m.ValidateExclusion("Login", "root", "admin", "root")
+	assertEqual(t, []string{"is not included in the list"}, m.GetErrors()["Role"])
+	assertEqual(t, []string{"is reserved"}, m.GetErrors()["Login"])
+}
+
+func TestModelValidateConfirmation(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateConfirmation("password", "secret", "secret")
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateConfirmation("password", "secret", "secrets")
+	assertEqual(t, []string{"doesn't match password"}, m.GetErrors()["password_confirmation"])
+}
+
+func TestModelValidateDateRange(t *testing.T) {
+	m.ResetErrors()
+
+	min := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+	m.ValidateDateRange("Date", min, min, max)
+	m.ValidateDateRange("Date", max.AddDate(1, 0, 0), min, time.Time{})
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateDateRange("Date", max.AddDate(0, 0, 1), min, max)
+	assertEqual(t, []string{"must be on or before 2020-12-31T00:00:00Z"}, m.GetErrors()["Date"])
+}
+
+func TestModelValidateSliceLength(t *testing.T) {
+	m.ResetErrors()
+
+	m.ValidateSliceLength("Tags", []string{"a"}, 1, -1)
+	assertEqual(t, m.IsValid(), true)
+
+	m.ValidateSliceLength("Tags", []string{"a", "b"}, -1, 1)
+	assertEqual(t, []string{"must have at most 1 items"}, m.GetErrors()["Tags"])
+}
+
+func TestModelValidateUniqueness(t *testing.T) {
+	m.ResetErrors()
+
+	taken := func(v interface{}) (bool, error) { return v == "john", nil }
+	assertNil(t, m.ValidateUniqueness("Login", "mary", taken))
+	assertEqual(t, m.IsValid(), true)
+
+	assertNil(t, m.ValidateUniqueness("Login", "john", taken))
+	assertEqual(t, []string{"has already been taken"}, m.GetErrors()["Login"])
+
+	err := errors.New("db error")
+	assertEqual(t, err, m.ValidateUniqueness("Login", "john", func(interface{}) (bool, error) { return false, err }))
+}